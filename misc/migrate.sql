@@ -1 +1,87 @@
-create index on wf.process_instance using gin ((state->'processor'));
\ No newline at end of file
+create index on wf.process_instance using gin ((state->'processor'));
+
+-- 根据未结束流程实例的state生成待办任务(wf.task)
+insert into wf.task (process_instance_id, process_definition_id, node_id, node_label, assignee, status, is_counter_sign, tenant_id, create_time)
+select pi.id,
+       pi.process_definition_id,
+       s ->> 'id',
+       s ->> 'label',
+       p.assignee,
+       1,
+       coalesce((s ->> 'isCounterSign')::boolean, false),
+       pi.tenant_id,
+       pi.update_time
+from wf.process_instance pi
+         cross join jsonb_array_elements(pi.state) s
+         cross join jsonb_array_elements_text(
+        case when jsonb_typeof(s -> 'unCompletedProcessor') = 'array' then s -> 'unCompletedProcessor' else '[]'::jsonb end
+    ) as p(assignee)
+where pi.is_end = false
+  and pi.is_denied = false
+  and jsonb_typeof(pi.state) = 'array'
+  and p.assignee <> ''
+  and not exists(select 1
+                 from wf.task t
+                 where t.process_instance_id = pi.id
+                   and t.node_id = s ->> 'id'
+                   and t.assignee = p.assignee
+                   and t.status = 1);
+
+-- 用户标识不再要求是数字, 相关人改为text[]
+alter table wf.process_instance alter column related_person drop default;
+alter table wf.process_instance alter column related_person type text[] using related_person::text[];
+alter table wf.process_instance alter column related_person set default array[]::text[];
+
+-- 租户编码改为唯一索引: 合并重复的租户(保留id最小的一条), 关联数据迁移到保留的租户上
+create temporary table tenant_duplicate as
+select t.id as duplicate_id, k.keep_id
+from wf.tenant t
+         inner join (select name, min(id) as keep_id from wf.tenant group by name having count(1) > 1) k on k.name = t.name
+where t.id <> k.keep_id;
+
+update wf.api_key set tenant_id = d.keep_id from tenant_duplicate d where api_key.tenant_id = d.duplicate_id;
+update wf.classify set tenant_id = d.keep_id from tenant_duplicate d where classify.tenant_id = d.duplicate_id;
+update wf.process_definition set tenant_id = d.keep_id from tenant_duplicate d where process_definition.tenant_id = d.duplicate_id;
+update wf.process_definition_version set tenant_id = d.keep_id from tenant_duplicate d where process_definition_version.tenant_id = d.duplicate_id;
+update wf.process_instance set tenant_id = d.keep_id from tenant_duplicate d where process_instance.tenant_id = d.duplicate_id;
+update wf.task set tenant_id = d.keep_id from tenant_duplicate d where task.tenant_id = d.duplicate_id;
+delete from wf.idempotency_record using tenant_duplicate d where idempotency_record.tenant_id = d.duplicate_id;
+update wf.variable_history set tenant_id = d.keep_id from tenant_duplicate d where variable_history.tenant_id = d.duplicate_id;
+update wf.user set tenant_id = d.keep_id from tenant_duplicate d where "user".tenant_id = d.duplicate_id;
+update wf.role set tenant_id = d.keep_id from tenant_duplicate d where role.tenant_id = d.duplicate_id;
+update wf.department set tenant_id = d.keep_id from tenant_duplicate d where department.tenant_id = d.duplicate_id;
+delete from wf.tenant using tenant_duplicate d where tenant.id = d.duplicate_id;
+drop table tenant_duplicate;
+
+drop index if exists wf.idx_wf_tenant_name;
+create unique index idx_wf_tenant_name on wf.tenant (name);
+
+-- 流程定义的版本快照改为(流程定义id, 版本号)唯一: 删除重复的快照(保留id最大的一条)
+delete from wf.process_definition_version v
+    using wf.process_definition_version d
+where v.process_definition_id = d.process_definition_id
+  and v.version = d.version
+  and v.id < d.id;
+
+create unique index if not exists idx_definition_version on wf.process_definition_version (process_definition_id, version);
+
+-- 已有的流程定义补充当前版本的快照, 否则查询历史版本和版本对比时找不到该版本
+insert into wf.process_definition_version (process_definition_id, version, name, structure, tenant_id, create_by, create_time)
+select d.id,
+       d.version,
+       d.name,
+       d.structure,
+       d.tenant_id,
+       coalesce(nullif(d.update_by, ''), d.create_by),
+       d.update_time
+from wf.process_definition d
+where not exists(select 1
+                 from wf.process_definition_version v
+                 where v.process_definition_id = d.id
+                   and v.version = d.version);
+
+-- 流转历史增加管理员操作的标记, "我处理的"不包含管理员操作的记录
+alter table wf.circulation_history add column if not exists is_admin boolean default false;
+update wf.circulation_history
+set is_admin = true
+where circulation in ('管理员跳转', '管理员转派', '管理员终止', '管理员删除', '管理员恢复');
@@ -6,13 +6,19 @@
 package config
 
 type Config struct {
-	App App `yaml:"app"`
-	Db  Db  `yaml:"db"`
+	App        App        `yaml:"app"`
+	Db         Db         `yaml:"db"`
+	Auth       Auth       `yaml:"auth"`
+	Expression Expression `yaml:"expression"`
 }
 
 type App struct {
-	Name          string `yaml:"name"`
-	EnableSwagger bool   `yaml:"enable_swagger"`
+	Name             string `yaml:"name"`
+	EnableSwagger    bool   `yaml:"enable_swagger"`
+	AdminRole        string `yaml:"admin_role"`                        // 租户管理员的角色标识, 拥有该角色的用户可以查看租户下所有的流程实例
+	AdminKey         string `yaml:"admin_key"`                         // 管理接口(/api/wf/admin)的密钥, 为空则不开放管理接口
+	AutoCreateTenant bool   `yaml:"auto_create_tenant" default:"true"` // 请求头中的租户不存在时是否自动创建
+	IdempotencyTtl   int    `yaml:"idempotency_ttl" default:"1440"`    // Idempotency-Key的有效期, 单位分钟
 }
 
 type Db struct {
@@ -24,3 +30,34 @@ type Db struct {
 	LogMode     bool   `yaml:"log_mode"`
 	AutoMigrate bool   `yaml:"auto_migrate"`
 }
+
+// 认证方式, 按照 api key -> jwt -> 可信网关请求头 的顺序尝试
+type Auth struct {
+	TrustedGateway bool `yaml:"trusted_gateway"`               // 是否信任网关传递的WF-CURRENT-USER请求头, 只有在网关已经完成认证时才能开启
+	AllowMixedMode bool `yaml:"allow_mixed_mode"`              // 开启了api key或jwt的时候是否仍然信任WF-CURRENT-USER请求头, 需要显式开启
+	EnableApiKey   bool `yaml:"enable_api_key" default:"true"` // 是否允许使用租户的api key(服务之间调用)
+	Jwt            Jwt  `yaml:"jwt"`
+}
+
+type Jwt struct {
+	Enable              bool   `yaml:"enable"`
+	HmacSecret          string `yaml:"hmac_secret"`                        // HS256/HS384/HS512的密钥
+	PublicKeyFile       string `yaml:"public_key_file"`                    // RS256/RS384/RS512的PEM格式公钥文件
+	JwksFile            string `yaml:"jwks_file"`                          // 本地的JWKS文件
+	JwksUrl             string `yaml:"jwks_url"`                           // 远程的JWKS地址, 比如OIDC的jwks_uri
+	JwksRefreshInterval int    `yaml:"jwks_refresh_interval" default:"60"` // 远程JWKS的刷新间隔, 单位分钟
+	Issuer              string `yaml:"issuer"`                             // 校验iss, 为空则不校验
+	Audience            string `yaml:"audience"`                           // 校验aud, 为空则不校验
+	UserClaim           string `yaml:"user_claim" default:"sub"`           // 用户标识所在的claim
+	TenantClaim         string `yaml:"tenant_claim"`                       // 租户编码所在的claim, 为空则使用WF-TENANT-CODE请求头
+	Leeway              int    `yaml:"leeway" default:"60"`                // exp/nbf校验允许的时钟偏差, 单位秒
+}
+
+// 条件表达式的执行限制
+type Expression struct {
+	MaxLength       int `yaml:"max_length" default:"4096"`     // 表达式的最大长度
+	MaxNodes        int `yaml:"max_nodes" default:"1000"`      // 表达式语法树的最大节点数
+	MaxRange        int `yaml:"max_range" default:"10000"`     // 范围(a..b)的最大元素个数
+	MaxClosureDepth int `yaml:"max_closure_depth" default:"2"` // all/any/filter/map等闭包的最大嵌套层数
+	CacheTtl        int `yaml:"cache_ttl" default:"60"`        // 编译结果的缓存时间, 单位分钟
+}
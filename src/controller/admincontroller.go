@@ -0,0 +1,129 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/19 11:40
+ * @Desc: 管理员对流程实例的特权操作
+ */
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"workflow/src/global/response"
+	"workflow/src/model/request"
+	"workflow/src/service"
+)
+
+// @Tags process-instances
+// @Summary 管理员强制跳转到指定节点
+// @Accept  json
+// @Produce json
+// @param request body request.AdminJumpRequest true "request"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/process-instances/_jump [POST]
+func AdminJumpProcessInstance(c echo.Context) error {
+	var r request.AdminJumpRequest
+	if err := c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	instance, err := service.AdminJumpProcessInstance(&r, c)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, instance)
+}
+
+// @Tags process-instances
+// @Summary 管理员修改当前节点的处理人
+// @Accept  json
+// @Produce json
+// @param request body request.AdminReassignRequest true "request"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/process-instances/_reassign [POST]
+func AdminReassignProcessInstance(c echo.Context) error {
+	var r request.AdminReassignRequest
+	if err := c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	instance, err := service.AdminReassignProcessInstance(&r, c)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, instance)
+}
+
+// @Tags process-instances
+// @Summary 管理员终止流程
+// @Accept  json
+// @Produce json
+// @param request body request.AdminTerminateRequest true "request"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/process-instances/_terminate [POST]
+func AdminTerminateProcessInstance(c echo.Context) error {
+	var r request.AdminTerminateRequest
+	if err := c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	instance, err := service.AdminTerminateProcessInstance(&r, c)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, instance)
+}
+
+// @Tags process-instances
+// @Summary 管理员删除流程实例(可以恢复)
+// @Produce json
+// @param id path string true "request"
+// @param remarks query string false "备注"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/process-instances/{id} [DELETE]
+func AdminDeleteProcessInstance(c echo.Context) error {
+	var r request.AdminDeleteRequest
+	if err := c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	err := service.AdminDeleteProcessInstance(&r, c)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.Ok(c)
+}
+
+// @Tags process-instances
+// @Summary 管理员恢复被删除的流程实例
+// @Produce json
+// @param id path string true "request"
+// @param remarks query string false "备注"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/process-instances/{id}/_undelete [POST]
+func AdminUndeleteProcessInstance(c echo.Context) error {
+	var r request.AdminDeleteRequest
+	if err := c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	err := service.AdminUndeleteProcessInstance(&r, c)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.Ok(c)
+}
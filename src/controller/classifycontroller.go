@@ -0,0 +1,171 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/15 10:48
+ * @Desc: 流程分类
+ */
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"workflow/src/global"
+	"workflow/src/global/response"
+	"workflow/src/model/request"
+	"workflow/src/service"
+	"workflow/src/util"
+)
+
+// @Tags classifies
+// @Summary 创建流程分类
+// @Accept  json
+// @Produce json
+// @param request body request.ClassifyRequest true "request"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/classifies [POST]
+func CreateClassify(c echo.Context) error {
+	var (
+		r   request.ClassifyRequest
+		err error
+	)
+
+	if err = c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	// 验证
+	tenantId := util.GetCurrentTenantId(c)
+	err = service.ValidateClassifyRequest(&r, 0, tenantId)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	classify, err := service.CreateClassify(&r, c)
+	if err != nil {
+		global.BankLogger.Error("CreateClassify错误", err)
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, classify)
+}
+
+// @Tags classifies
+// @Summary 更新流程分类
+// @Accept  json
+// @Produce json
+// @param request body request.ClassifyRequest true "request"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/classifies [PUT]
+func UpdateClassify(c echo.Context) error {
+	var (
+		r   request.ClassifyRequest
+		err error
+	)
+
+	if err = c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	// 验证
+	tenantId := util.GetCurrentTenantId(c)
+	err = service.ValidateClassifyRequest(&r, r.Id, tenantId)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	err = service.UpdateClassify(&r, c)
+	if err != nil {
+		global.BankLogger.Error("UpdateClassify错误", err)
+		return response.Failed(c, err)
+	}
+
+	return response.Ok(c)
+}
+
+// @Tags classifies
+// @Summary 删除流程分类
+// @Produce json
+// @param id path string true "request"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/classifies/{id} [DELETE]
+func DeleteClassify(c echo.Context) error {
+	classifyId := c.Param("id")
+	if classifyId == "" {
+		return response.BadRequestWithMessage(c, "参数不正确，请确定参数classifyId是否传递")
+	}
+
+	tenantId := util.GetCurrentTenantId(c)
+	err := service.DeleteClassify(util.StringToInt(classifyId), tenantId)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.Ok(c)
+}
+
+// @Tags classifies
+// @Summary 获取流程分类详情
+// @Produce json
+// @param id path string true "request"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/classifies/{id} [GET]
+func GetClassify(c echo.Context) error {
+	classifyId := c.Param("id")
+	if classifyId == "" {
+		return response.BadRequestWithMessage(c, "参数不正确，请确定参数classifyId是否传递")
+	}
+
+	tenantId := util.GetCurrentTenantId(c)
+	classify, err := service.GetClassify(util.StringToInt(classifyId), tenantId)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, classify)
+}
+
+// @Tags classifies
+// @Summary 获取流程分类树
+// @Produce json
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/classifies [GET]
+func ListClassifyTree(c echo.Context) error {
+	tenantId := util.GetCurrentTenantId(c)
+	tree, err := service.GetClassifyTree(tenantId)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, tree)
+}
+
+// @Tags process-definitions
+// @Summary 按分类分组获取流程模板(发起流程页面使用)
+// @Produce json
+// @param request query request.GroupedDefinitionRequest true "request"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/process-definitions/_grouped [GET]
+func ListGroupedProcessDefinitions(c echo.Context) error {
+	var r request.GroupedDefinitionRequest
+	if err := c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	groups, err := service.GetGroupedDefinitions(&r, c)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, groups)
+}
@@ -6,6 +6,8 @@
 package controller
 
 import (
+	"net/http"
+
 	"github.com/labstack/echo/v4"
 
 	"workflow/src/global"
@@ -124,7 +126,7 @@ func GetProcessDefinition(c echo.Context) error {
 	}
 
 	tenantId := util.GetCurrentTenantId(c)
-	definition, err := service.GetDefinition(util.StringToInt(definitionId), tenantId)
+	definition, err := service.GetDefinitionDetail(util.StringToInt(definitionId), tenantId)
 	if err != nil {
 		return response.Failed(c, err)
 	}
@@ -156,6 +158,99 @@ func ListProcessDefinition(c echo.Context) error {
 	return response.OkWithData(c, definitions)
 }
 
+// @Tags process-definitions
+// @Summary 获取当前用户可以发起的流程模板列表
+// @Produce json
+// @param request query request.StartableDefinitionRequest true "request"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/process-definitions/_startable [GET]
+func ListStartableProcessDefinitions(c echo.Context) error {
+	var r request.StartableDefinitionRequest
+	if err := c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	definitions, err := service.ListStartableDefinitions(&r, c)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, definitions)
+}
+
+// @Tags process-definitions
+// @Summary 导出流程模板为mermaid或者graphviz dot
+// @Produce plain
+// @param id path string true "request"
+// @param format query string true "mermaid或者dot"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {string} string "mermaid/dot"
+// @Router /api/wf/process-definitions/{id}/export [GET]
+func ExportProcessDefinition(c echo.Context) error {
+	definitionId := c.Param("id")
+	if definitionId == "" {
+		return response.BadRequestWithMessage(c, "参数不正确，请确定参数processDefinitionId是否传递")
+	}
+
+	tenantId := util.GetCurrentTenantId(c)
+	content, err := service.ExportDefinition(util.StringToInt(definitionId), c.QueryParam("format"), tenantId)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return c.String(http.StatusOK, content)
+}
+
+// @Tags process-definitions
+// @Summary 对比两个流程模板(或者同一个流程模板的两个版本)
+// @Produce json
+// @param request query request.DefinitionDiffRequest true "request"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/process-definitions/_diff [GET]
+func DiffProcessDefinition(c echo.Context) error {
+	var r request.DefinitionDiffRequest
+	if err := c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	tenantId := util.GetCurrentTenantId(c)
+	diff, err := service.DiffDefinition(&r, tenantId)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, diff)
+}
+
+// @Tags process-definitions
+// @Summary 获取流程模板的历史版本列表
+// @Produce json
+// @param id path int true "流程模板id"
+// @param request query request.DefinitionVersionListRequest true "request"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/process-definitions/{id}/versions [GET]
+func ListProcessDefinitionVersions(c echo.Context) error {
+	var r request.DefinitionVersionListRequest
+	if err := c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	tenantId := util.GetCurrentTenantId(c)
+	versions, err := service.ListDefinitionVersions(&r, tenantId)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, versions)
+}
+
 var (
 	DynamicPrefix = "/api"
 )
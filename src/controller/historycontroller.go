@@ -29,10 +29,6 @@ func ListHistory(c echo.Context) error {
 		return response.BadRequest(c)
 	}
 
-	if r.Sort == "" {
-		r.Sort = "id"
-	}
-
 	if r.Order == "" {
 		r.Order = "desc"
 	}
@@ -6,13 +6,18 @@
 package controller
 
 import (
+	"fmt"
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
 	"workflow/src/global/response"
 	"workflow/src/model/request"
 	"workflow/src/service"
+	"workflow/src/service/event"
+	"workflow/src/util"
 )
 
 // @Tags process-instances
@@ -22,6 +27,7 @@ import (
 // @param request body request.ProcessInstanceRequest true "request"
 // @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
 // @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @param Idempotency-Key header string false "重试时使用相同的值, 避免重复提交"
 // @Success 200 {object} response.HttpResponse
 // @Router /api/wf/process-instances [post]
 func CreateProcessInstance(c echo.Context) error {
@@ -69,6 +75,7 @@ func ListProcessInstances(c echo.Context) error {
 // @param request body request.HandleInstancesRequest true "request"
 // @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
 // @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @param Idempotency-Key header string false "重试时使用相同的值, 避免重复提交"
 // @Success 200 {object} response.HttpResponse
 // @Router /api/wf/process-instances/_handle [POST]
 func HandleProcessInstance(c echo.Context) error {
@@ -85,6 +92,30 @@ func HandleProcessInstance(c echo.Context) error {
 	return response.OkWithData(c, instance)
 }
 
+// @Tags process-instances
+// @Summary 批量审批/否决流程, 每一项单独处理, 返回每一项的处理结果
+// @Accept  json
+// @Produce json
+// @param request body request.BatchHandleInstancesRequest true "request"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @param Idempotency-Key header string false "重试时使用相同的值, 避免重复提交"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/process-instances/_batch-handle [POST]
+func BatchHandleProcessInstances(c echo.Context) error {
+	var r request.BatchHandleInstancesRequest
+	if err := c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	result, err := service.BatchHandleProcessInstances(&r, c)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, result)
+}
+
 // @Tags process-instances
 // @Summary 否决流程流程
 // @Accept  json
@@ -92,6 +123,7 @@ func HandleProcessInstance(c echo.Context) error {
 // @param request body request.DenyInstanceRequest true "request"
 // @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
 // @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @param Idempotency-Key header string false "重试时使用相同的值, 避免重复提交"
 // @Success 200 {object} response.HttpResponse
 // @Router /api/wf/process-instances/_deny [POST]
 func DenyProcessInstance(c echo.Context) error {
@@ -131,6 +163,28 @@ func GetProcessInstance(c echo.Context) error {
 	return response.OkWithData(c, instance)
 }
 
+// @Tags process-instances
+// @Summary 获取当前用户对流程实例可以进行的操作(可以走的顺序流、是否可以否决等)
+// @Produce json
+// @param id path int true "request"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/process-instances/{id}/actions [GET]
+func GetInstanceActions(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.BadRequest(c)
+	}
+
+	actions, err := service.GetInstanceActions(id, c)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, actions)
+}
+
 // @Tags process-instances
 // @Summary 获取流程链路
 // @Produce json
@@ -153,6 +207,90 @@ func GetProcessTrain(c echo.Context) error {
 	return response.OkWithData(c, trainNodes)
 }
 
+// @Tags process-instances
+// @Summary 获取流程实例的svg流程图
+// @Produce image/svg+xml
+// @param id path int true "request"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {string} string "svg"
+// @Router /api/wf/process-instances/{id}/diagram.svg [GET]
+func GetProcessInstanceDiagram(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.BadRequest(c)
+	}
+
+	svg, err := service.GetProcessInstanceDiagram(id, c)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return c.Blob(http.StatusOK, "image/svg+xml", svg)
+}
+
+// @Tags process-instances
+// @Summary 获取各个列表类型的数量(待办角标)
+// @Produce json
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/process-instances/_counts [GET]
+func CountProcessInstances(c echo.Context) error {
+	counts, err := service.CountProcessInstances(c)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, counts)
+}
+
+// @Tags process-instances
+// @Summary 订阅当前用户的待办和流程实例变化(Server-Sent Events)
+// @Produce text/event-stream
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {string} string "event stream"
+// @Router /api/wf/process-instances/_stream [GET]
+func StreamProcessInstanceEvents(c echo.Context) error {
+	tenantId, userIdentifier := util.GetWorkContext(c)
+	messages, cancel := event.Subscribe(tenantId, userIdentifier)
+	defer cancel()
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, "text/event-stream")
+	resp.Header().Set("Cache-Control", "no-cache")
+	resp.Header().Set("Connection", "keep-alive")
+	resp.WriteHeader(http.StatusOK)
+	resp.Flush()
+
+	// 定时发送心跳, 避免被网关断开
+	heartbeat := time.NewTicker(30 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(resp, ": ping\n\n"); err != nil {
+				return nil
+			}
+			resp.Flush()
+
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			if _, err := fmt.Fprintf(resp, "event: %s\ndata: %s\n\n", message.Type, util.MarshalToString(message)); err != nil {
+				return nil
+			}
+			resp.Flush()
+		}
+	}
+}
+
 // 获取流程实例中的变量
 //func GetInstanceVariable(c echo.Context) error {
 //	var r request.GetVariableRequest
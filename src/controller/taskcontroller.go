@@ -0,0 +1,80 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/13 14:52
+ * @Desc: 待办任务
+ */
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"workflow/src/global/response"
+	"workflow/src/model/request"
+	"workflow/src/service"
+)
+
+// @Tags tasks
+// @Summary 获取当前用户的任务列表
+// @Produce json
+// @param request query request.TaskListRequest true "request"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/tasks [GET]
+func ListTasks(c echo.Context) error {
+	var r request.TaskListRequest
+	if err := c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	tasks, err := service.ListTasks(&r, c)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, tasks)
+}
+
+// @Tags tasks
+// @Summary 获取当前用户的抄送列表
+// @Produce json
+// @param request query request.CcTaskListRequest true "request"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/tasks/cc [GET]
+func ListCcTasks(c echo.Context) error {
+	var r request.CcTaskListRequest
+	if err := c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	tasks, err := service.ListCcTasks(&r, c)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, tasks)
+}
+
+// @Tags tasks
+// @Summary 抄送标记为已读
+// @Produce json
+// @param id path int true "抄送任务的id"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/tasks/{id}/_read [POST]
+func ReadCcTask(c echo.Context) error {
+	var r request.ReadCcTaskRequest
+	if err := c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	err := service.ReadCcTask(&r, c)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.Ok(c)
+}
@@ -0,0 +1,227 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/16 15:30
+ * @Desc: 租户管理
+ */
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"workflow/src/global"
+	"workflow/src/global/response"
+	"workflow/src/model/request"
+	"workflow/src/service"
+	"workflow/src/util"
+)
+
+// @Tags admin
+// @Summary 创建租户
+// @Accept  json
+// @Produce json
+// @param request body request.TenantRequest true "request"
+// @param WF-ADMIN-KEY header string true "WF-ADMIN-KEY"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/admin/tenants [POST]
+func CreateTenant(c echo.Context) error {
+	var (
+		r   request.TenantRequest
+		err error
+	)
+
+	if err = c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	// 验证
+	err = service.ValidateTenantRequest(&r, 0)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	tenant, err := service.CreateTenant(&r)
+	if err != nil {
+		global.BankLogger.Error("CreateTenant错误", err)
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, tenant)
+}
+
+// @Tags admin
+// @Summary 更新租户(包括修改租户编码)
+// @Accept  json
+// @Produce json
+// @param request body request.TenantRequest true "request"
+// @param WF-ADMIN-KEY header string true "WF-ADMIN-KEY"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/admin/tenants [PUT]
+func UpdateTenant(c echo.Context) error {
+	var (
+		r   request.TenantRequest
+		err error
+	)
+
+	if err = c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	// 验证
+	err = service.ValidateTenantRequest(&r, r.Id)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	err = service.UpdateTenant(&r)
+	if err != nil {
+		global.BankLogger.Error("UpdateTenant错误", err)
+		return response.Failed(c, err)
+	}
+
+	return response.Ok(c)
+}
+
+// @Tags admin
+// @Summary 获取租户详情
+// @Produce json
+// @param id path string true "request"
+// @param WF-ADMIN-KEY header string true "WF-ADMIN-KEY"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/admin/tenants/{id} [GET]
+func GetTenant(c echo.Context) error {
+	tenantId := c.Param("id")
+	if tenantId == "" {
+		return response.BadRequestWithMessage(c, "参数不正确，请确定参数tenantId是否传递")
+	}
+
+	tenant, err := service.GetTenant(util.StringToInt(tenantId))
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, tenant)
+}
+
+// @Tags admin
+// @Summary 获取租户列表
+// @Produce json
+// @param request query request.TenantListRequest true "request"
+// @param WF-ADMIN-KEY header string true "WF-ADMIN-KEY"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/admin/tenants [GET]
+func ListTenants(c echo.Context) error {
+	var r request.TenantListRequest
+	if err := c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	tenants, err := service.ListTenants(&r)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, tenants)
+}
+
+// @Tags admin
+// @Summary 禁用租户, 禁用后该租户的所有请求都会被拒绝
+// @Produce json
+// @param id path string true "request"
+// @param WF-ADMIN-KEY header string true "WF-ADMIN-KEY"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/admin/tenants/{id}/_disable [POST]
+func DisableTenant(c echo.Context) error {
+	return setTenantDisabled(c, true)
+}
+
+// @Tags admin
+// @Summary 启用租户
+// @Produce json
+// @param id path string true "request"
+// @param WF-ADMIN-KEY header string true "WF-ADMIN-KEY"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/admin/tenants/{id}/_enable [POST]
+func EnableTenant(c echo.Context) error {
+	return setTenantDisabled(c, false)
+}
+
+func setTenantDisabled(c echo.Context, disabled bool) error {
+	tenantId := c.Param("id")
+	if tenantId == "" {
+		return response.BadRequestWithMessage(c, "参数不正确，请确定参数tenantId是否传递")
+	}
+
+	err := service.SetTenantDisabled(util.StringToInt(tenantId), disabled)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.Ok(c)
+}
+
+// @Tags admin
+// @Summary 为租户创建api key, key的明文只在创建时返回一次
+// @Accept  json
+// @Produce json
+// @param id path int true "租户id"
+// @param request body request.ApiKeyRequest true "request"
+// @param WF-ADMIN-KEY header string true "WF-ADMIN-KEY"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/admin/tenants/{id}/api-keys [POST]
+func CreateApiKey(c echo.Context) error {
+	var r request.ApiKeyRequest
+	if err := c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	apiKey, err := service.CreateApiKey(&r)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, apiKey)
+}
+
+// @Tags admin
+// @Summary 获取租户的api key列表
+// @Produce json
+// @param id path int true "租户id"
+// @param WF-ADMIN-KEY header string true "WF-ADMIN-KEY"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/admin/tenants/{id}/api-keys [GET]
+func ListApiKeys(c echo.Context) error {
+	tenantId := c.Param("id")
+	if tenantId == "" {
+		return response.BadRequestWithMessage(c, "参数不正确，请确定参数tenantId是否传递")
+	}
+
+	apiKeys, err := service.ListApiKeys(util.StringToInt(tenantId))
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, apiKeys)
+}
+
+// @Tags admin
+// @Summary 禁用租户的api key
+// @Produce json
+// @param id path int true "租户id"
+// @param keyId path int true "api key的id"
+// @param WF-ADMIN-KEY header string true "WF-ADMIN-KEY"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/admin/tenants/{id}/api-keys/{keyId}/_disable [POST]
+func DisableApiKey(c echo.Context) error {
+	tenantId := c.Param("id")
+	keyId := c.Param("keyId")
+	if tenantId == "" || keyId == "" {
+		return response.BadRequestWithMessage(c, "参数不正确，请确定参数tenantId和keyId是否传递")
+	}
+
+	err := service.DisableApiKey(util.StringToInt(tenantId), util.StringToInt(keyId))
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.Ok(c)
+}
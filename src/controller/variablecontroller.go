@@ -0,0 +1,106 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/21 11:20
+ * @Desc: 流程变量
+ */
+package controller
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"workflow/src/global/response"
+	"workflow/src/model/request"
+	"workflow/src/service"
+)
+
+// @Tags process-instances
+// @Summary 获取流程实例的变量列表
+// @Produce json
+// @param id path int true "实例id"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/process-instances/{id}/variables [GET]
+func ListInstanceVariables(c echo.Context) error {
+	var r request.GetVariableListRequest
+	if err := c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	variables, err := service.ListInstanceVariables(&r, c)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, variables)
+}
+
+// @Tags process-instances
+// @Summary 获取流程实例的单个变量
+// @Produce json
+// @param id path int true "实例id"
+// @param name path string true "变量名"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/process-instances/{id}/variables/{name} [GET]
+func GetInstanceVariable(c echo.Context) error {
+	var r request.GetVariableRequest
+	if err := c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	variable, err := service.GetInstanceVariable(&r, c)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, variable)
+}
+
+// @Tags process-instances
+// @Summary 获取流程实例变量的变更历史
+// @Produce json
+// @param id path int true "实例id"
+// @param request query request.GetVariableListRequest true "request"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/process-instances/{id}/variable-history [GET]
+func ListVariableHistory(c echo.Context) error {
+	var r request.GetVariableListRequest
+	if err := c.Bind(&r); err != nil {
+		return response.BadRequest(c)
+	}
+
+	histories, err := service.ListVariableHistory(&r, c)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, histories)
+}
+
+// @Tags process-instances
+// @Summary 获取流程实例的时间线(流转记录和变量变更)
+// @Produce json
+// @param id path int true "实例id"
+// @param WF-TENANT-CODE header string true "WF-TENANT-CODE"
+// @param WF-CURRENT-USER header string true "WF-CURRENT-USER"
+// @Success 200 {object} response.HttpResponse
+// @Router /api/wf/process-instances/{id}/timeline [GET]
+func GetInstanceTimeline(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.BadRequest(c)
+	}
+
+	timeline, err := service.GetInstanceTimeline(id, c)
+	if err != nil {
+		return response.Failed(c, err)
+	}
+
+	return response.OkWithData(c, timeline)
+}
@@ -24,22 +24,25 @@ var doc = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/wf/process-definitions": {
+        "/api/wf/admin/tenants": {
             "get": {
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "process-definitions"
+                    "admin"
                 ],
-                "summary": "获取流程定义列表",
+                "summary": "获取租户列表",
                 "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "是否已禁用, 不传则返回全部",
+                        "name": "isDisabled",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "关键词",
+                        "description": "租户编码或者显示名称的关键词",
                         "name": "keyword",
                         "in": "query"
                     },
@@ -63,27 +66,14 @@ var doc = `{
                     },
                     {
                         "type": "string",
-                        "description": "排序键的名字，在各查询实现中默认值与可用值都不同",
+                        "description": "排序键的名字，在各查询实现中默认值与可用值都不同, 不可用的会返回400",
                         "name": "sort",
                         "in": "query"
                     },
-                    {
-                        "type": "integer",
-                        "description": "类别 1=我创建的  2=所有",
-                        "name": "type",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "WF-TENANT-CODE",
-                        "name": "WF-TENANT-CODE",
-                        "in": "header",
-                        "required": true
-                    },
                     {
                         "type": "string",
-                        "description": "WF-CURRENT-USER",
-                        "name": "WF-CURRENT-USER",
+                        "description": "WF-ADMIN-KEY",
+                        "name": "WF-ADMIN-KEY",
                         "in": "header",
                         "required": true
                     }
@@ -105,9 +95,9 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "process-definitions"
+                    "admin"
                 ],
-                "summary": "更新流程模板",
+                "summary": "更新租户(包括修改租户编码)",
                 "parameters": [
                     {
                         "description": "request",
@@ -115,20 +105,13 @@ var doc = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/request.ProcessDefinitionRequest"
+                            "$ref": "#/definitions/request.TenantRequest"
                         }
                     },
                     {
                         "type": "string",
-                        "description": "WF-TENANT-CODE",
-                        "name": "WF-TENANT-CODE",
-                        "in": "header",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "WF-CURRENT-USER",
-                        "name": "WF-CURRENT-USER",
+                        "description": "WF-ADMIN-KEY",
+                        "name": "WF-ADMIN-KEY",
                         "in": "header",
                         "required": true
                     }
@@ -150,9 +133,9 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "process-definitions"
+                    "admin"
                 ],
-                "summary": "创建流程模板",
+                "summary": "创建租户",
                 "parameters": [
                     {
                         "description": "request",
@@ -160,20 +143,48 @@ var doc = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/request.ProcessDefinitionRequest"
+                            "$ref": "#/definitions/request.TenantRequest"
                         }
                     },
                     {
                         "type": "string",
-                        "description": "WF-TENANT-CODE",
-                        "name": "WF-TENANT-CODE",
+                        "description": "WF-ADMIN-KEY",
+                        "name": "WF-ADMIN-KEY",
                         "in": "header",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/admin/tenants/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "获取租户详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "request",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "WF-CURRENT-USER",
-                        "name": "WF-CURRENT-USER",
+                        "description": "WF-ADMIN-KEY",
+                        "name": "WF-ADMIN-KEY",
                         "in": "header",
                         "required": true
                     }
@@ -188,39 +199,27 @@ var doc = `{
                 }
             }
         },
-        "/api/wf/process-definitions/_clone": {
+        "/api/wf/admin/tenants/{id}/_disable": {
             "post": {
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "process-definitions"
+                    "admin"
                 ],
-                "summary": "克隆流程定义列表",
+                "summary": "禁用租户, 禁用后该租户的所有请求都会被拒绝",
                 "parameters": [
-                    {
-                        "description": "request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/request.CloneDefinitionRequest"
-                        }
-                    },
                     {
                         "type": "string",
-                        "description": "WF-TENANT-CODE",
-                        "name": "WF-TENANT-CODE",
-                        "in": "header",
+                        "description": "request",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "WF-CURRENT-USER",
-                        "name": "WF-CURRENT-USER",
+                        "description": "WF-ADMIN-KEY",
+                        "name": "WF-ADMIN-KEY",
                         "in": "header",
                         "required": true
                     }
@@ -235,15 +234,15 @@ var doc = `{
                 }
             }
         },
-        "/api/wf/process-definitions/{id}": {
-            "get": {
+        "/api/wf/admin/tenants/{id}/_enable": {
+            "post": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "process-definitions"
+                    "admin"
                 ],
-                "summary": "获取流程模板详情",
+                "summary": "启用租户",
                 "parameters": [
                     {
                         "type": "string",
@@ -254,15 +253,43 @@ var doc = `{
                     },
                     {
                         "type": "string",
-                        "description": "WF-TENANT-CODE",
-                        "name": "WF-TENANT-CODE",
+                        "description": "WF-ADMIN-KEY",
+                        "name": "WF-ADMIN-KEY",
                         "in": "header",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/admin/tenants/{id}/api-keys": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "获取租户的api key列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "租户id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "WF-CURRENT-USER",
-                        "name": "WF-CURRENT-USER",
+                        "description": "WF-ADMIN-KEY",
+                        "name": "WF-ADMIN-KEY",
                         "in": "header",
                         "required": true
                     }
@@ -276,33 +303,38 @@ var doc = `{
                     }
                 }
             },
-            "delete": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "process-definitions"
+                    "admin"
                 ],
-                "summary": "删除流程模板",
+                "summary": "为租户创建api key, key的明文只在创建时返回一次",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "request",
+                        "type": "integer",
+                        "description": "租户id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "WF-TENANT-CODE",
-                        "name": "WF-TENANT-CODE",
-                        "in": "header",
-                        "required": true
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.ApiKeyRequest"
+                        }
                     },
                     {
                         "type": "string",
-                        "description": "WF-CURRENT-USER",
-                        "name": "WF-CURRENT-USER",
+                        "description": "WF-ADMIN-KEY",
+                        "name": "WF-ADMIN-KEY",
                         "in": "header",
                         "required": true
                     }
@@ -317,55 +349,58 @@ var doc = `{
                 }
             }
         },
-        "/api/wf/process-instances": {
-            "get": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/wf/admin/tenants/{id}/api-keys/{keyId}/_disable": {
+            "post": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "process-instances"
+                    "admin"
                 ],
-                "summary": "获取流程实例列表",
+                "summary": "禁用租户的api key",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "关键词",
-                        "name": "keyword",
-                        "in": "query"
-                    },
                     {
                         "type": "integer",
-                        "description": "取的条数",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "租户id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "跳过的条数",
-                        "name": "offset",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "asc或者是desc",
-                        "name": "order",
-                        "in": "query"
+                        "description": "api key的id",
+                        "name": "keyId",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "排序键的名字，在各查询实现中默认值与可用值都不同",
-                        "name": "sort",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "类别 1=我的待办 2=我创建的 3=和我相关的 4=所有",
-                        "name": "type",
-                        "in": "query"
-                    },
+                        "description": "WF-ADMIN-KEY",
+                        "name": "WF-ADMIN-KEY",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/classifies": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "classifies"
+                ],
+                "summary": "获取流程分类树",
+                "parameters": [
                     {
                         "type": "string",
                         "description": "WF-TENANT-CODE",
@@ -390,7 +425,7 @@ var doc = `{
                     }
                 }
             },
-            "post": {
+            "put": {
                 "consumes": [
                     "application/json"
                 ],
@@ -398,9 +433,9 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "process-instances"
+                    "classifies"
                 ],
-                "summary": "创建新的流程实例",
+                "summary": "更新流程分类",
                 "parameters": [
                     {
                         "description": "request",
@@ -408,7 +443,7 @@ var doc = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/request.ProcessInstanceRequest"
+                            "$ref": "#/definitions/request.ClassifyRequest"
                         }
                     },
                     {
@@ -434,9 +469,7 @@ var doc = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/wf/process-instances/_deny": {
+            },
             "post": {
                 "consumes": [
                     "application/json"
@@ -445,9 +478,9 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "process-instances"
+                    "classifies"
                 ],
-                "summary": "否决流程流程",
+                "summary": "创建流程分类",
                 "parameters": [
                     {
                         "description": "request",
@@ -455,7 +488,7 @@ var doc = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/request.DenyInstanceRequest"
+                            "$ref": "#/definitions/request.ClassifyRequest"
                         }
                     },
                     {
@@ -483,27 +516,22 @@ var doc = `{
                 }
             }
         },
-        "/api/wf/process-instances/_handle": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/wf/classifies/{id}": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "process-instances"
+                    "classifies"
                 ],
-                "summary": "处理/审批一个流程",
+                "summary": "获取流程分类详情",
                 "parameters": [
                     {
+                        "type": "string",
                         "description": "request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/request.HandleInstancesRequest"
-                        }
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
@@ -528,31 +556,23 @@ var doc = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/wf/process-instances/{id}": {
-            "get": {
+            },
+            "delete": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "process-instances"
+                    "classifies"
                 ],
-                "summary": "获取一个流程实例",
+                "summary": "删除流程分类",
                 "parameters": [
                     {
-                        "type": "integer",
+                        "type": "string",
                         "description": "request",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
-                    {
-                        "type": "boolean",
-                        "description": "request",
-                        "name": "includeProcessTrain",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
                         "description": "WF-TENANT-CODE",
@@ -578,26 +598,1721 @@ var doc = `{
                 }
             }
         },
-        "/api/wf/process-instances/{id}/history": {
+        "/api/wf/process-definitions": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-definitions"
+                ],
+                "summary": "获取流程定义列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "关键词",
+                        "name": "keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "取的条数",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "跳过的条数",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc或者是desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序键的名字，在各查询实现中默认值与可用值都不同, 不可用的会返回400",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "类别 1=我创建的  2=所有",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-definitions"
+                ],
+                "summary": "更新流程模板",
+                "parameters": [
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.ProcessDefinitionRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-definitions"
+                ],
+                "summary": "创建流程模板",
+                "parameters": [
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.ProcessDefinitionRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-definitions/_clone": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-definitions"
+                ],
+                "summary": "克隆流程定义列表",
+                "parameters": [
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.CloneDefinitionRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-definitions/_diff": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-definitions"
+                ],
+                "summary": "对比两个流程模板(或者同一个流程模板的两个版本)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "源流程定义id",
+                        "name": "sourceId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "源流程定义的版本, 不传则为当前版本",
+                        "name": "sourceVersion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "目标流程定义id, 不传则和源流程定义相同",
+                        "name": "targetId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "目标流程定义的版本, 不传则为当前版本",
+                        "name": "targetVersion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-definitions/_grouped": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-definitions"
+                ],
+                "summary": "按分类分组获取流程模板(发起流程页面使用)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "流程名称关键词",
+                        "name": "keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-definitions/_startable": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-definitions"
+                ],
+                "summary": "获取当前用户可以发起的流程模板列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "流程名称关键词",
+                        "name": "keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-definitions/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-definitions"
+                ],
+                "summary": "获取流程模板详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "request",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-definitions"
+                ],
+                "summary": "删除流程模板",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "request",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-definitions/{id}/export": {
+            "get": {
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "process-definitions"
+                ],
+                "summary": "导出流程模板为mermaid或者graphviz dot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "request",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "mermaid或者dot",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "mermaid/dot",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-definitions/{id}/versions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-definitions"
+                ],
+                "summary": "获取流程模板的历史版本列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "流程模板id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "取的条数",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "跳过的条数",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc或者是desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序键的名字，在各查询实现中默认值与可用值都不同, 不可用的会返回400",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "获取流程实例列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "分类id",
+                        "name": "classifyId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间止 yyyy-MM-dd HH:mm:ss",
+                        "name": "createTimeEnd",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间起 yyyy-MM-dd HH:mm:ss",
+                        "name": "createTimeStart",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "当前所在节点id",
+                        "name": "currentNodeId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "发起人",
+                        "name": "initiator",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "关键词",
+                        "name": "keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "取的条数",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "跳过的条数",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc或者是desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "优先级",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "流程定义id",
+                        "name": "processDefinitionId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序键的名字，在各查询实现中默认值与可用值都不同, 不可用的会返回400",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "状态 1=进行中 2=已结束 3=已否决",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "类别 1=我的待办 2=我创建的 3=和我相关的 4=所有(有权限查看的) 5=我处理过的",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间止 yyyy-MM-dd HH:mm:ss",
+                        "name": "updateTimeEnd",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间起 yyyy-MM-dd HH:mm:ss",
+                        "name": "updateTimeStart",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "创建新的流程实例",
+                "parameters": [
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.ProcessInstanceRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "重试时使用相同的值, 避免重复提交",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances/_batch-handle": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "批量审批/否决流程, 每一项单独处理, 返回每一项的处理结果",
+                "parameters": [
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.BatchHandleInstancesRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "重试时使用相同的值, 避免重复提交",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances/_counts": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "获取各个列表类型的数量(待办角标)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances/_deny": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "否决流程流程",
+                "parameters": [
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.DenyInstanceRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "重试时使用相同的值, 避免重复提交",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances/_handle": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "处理/审批一个流程",
+                "parameters": [
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.HandleInstancesRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "重试时使用相同的值, 避免重复提交",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances/_jump": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "管理员强制跳转到指定节点",
+                "parameters": [
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.AdminJumpRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances/_reassign": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "管理员修改当前节点的处理人",
+                "parameters": [
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.AdminReassignRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances/_stream": {
+            "get": {
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "订阅当前用户的待办和流程实例变化(Server-Sent Events)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "event stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances/_terminate": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "管理员终止流程",
+                "parameters": [
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.AdminTerminateRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "获取一个流程实例",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "request",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "request",
+                        "name": "includeProcessTrain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "管理员删除流程实例(可以恢复)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "request",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "备注",
+                        "name": "remarks",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances/{id}/_undelete": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "管理员恢复被删除的流程实例",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "request",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "备注",
+                        "name": "remarks",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances/{id}/actions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "获取当前用户对流程实例可以进行的操作(可以走的顺序流、是否可以否决等)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "request",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances/{id}/diagram.svg": {
+            "get": {
+                "produces": [
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "获取流程实例的svg流程图",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "request",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "svg",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances/{id}/history": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "获取流转历史列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "实例id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "关键词",
+                        "name": "keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "取的条数",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "跳过的条数",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc或者是desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序键的名字，在各查询实现中默认值与可用值都不同, 不可用的会返回400",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "类别 1=完整日志  2=简洁日志",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances/{id}/timeline": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "获取流程实例的时间线(流转记录和变量变更)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "实例id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances/{id}/train-nodes": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "获取流程链路",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "request",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances/{id}/variable-history": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "获取流程实例变量的变更历史",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "实例id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "取的条数",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "变量名, 查询变更历史的时候为空则查询所有变量",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "跳过的条数",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc或者是desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序键的名字，在各查询实现中默认值与可用值都不同, 不可用的会返回400",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances/{id}/variables": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "获取流程实例的变量列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "实例id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/process-instances/{id}/variables/{name}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-instances"
+                ],
+                "summary": "获取流程实例的单个变量",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "实例id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "变量名",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/role-users/_batch": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role-users"
+                ],
+                "summary": "批量同步(创建或更新)角色用户映射关系",
+                "parameters": [
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.BatchSyncUserRoleRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-TENANT-CODE",
+                        "name": "WF-TENANT-CODE",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "WF-CURRENT-USER",
+                        "name": "WF-CURRENT-USER",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.HttpResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wf/tasks": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "process-instances"
+                    "tasks"
                 ],
-                "summary": "获取流转历史列表",
+                "summary": "获取当前用户的任务列表",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "实例id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "string",
-                        "description": "关键词",
+                        "description": "关键词(流程实例标题)",
                         "name": "keyword",
                         "in": "query"
                     },
@@ -621,14 +2336,14 @@ var doc = `{
                     },
                     {
                         "type": "string",
-                        "description": "排序键的名字，在各查询实现中默认值与可用值都不同",
+                        "description": "排序键的名字，在各查询实现中默认值与可用值都不同, 不可用的会返回400",
                         "name": "sort",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "类别 1=完整日志  2=简洁日志",
-                        "name": "type",
+                        "description": "状态 1=待处理 2=已处理 3=已取消, 不传则为全部",
+                        "name": "status",
                         "in": "query"
                     },
                     {
@@ -656,22 +2371,51 @@ var doc = `{
                 }
             }
         },
-        "/api/wf/process-instances/{id}/train-nodes": {
+        "/api/wf/tasks/cc": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "process-instances"
+                    "tasks"
                 ],
-                "summary": "获取流程链路",
+                "summary": "获取当前用户的抄送列表",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "关键词(流程实例标题)",
+                        "name": "keyword",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "request",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "取的条数",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "跳过的条数",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc或者是desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序键的名字，在各查询实现中默认值与可用值都不同, 不可用的会返回400",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否只查询未读的抄送",
+                        "name": "unread",
+                        "in": "query"
                     },
                     {
                         "type": "string",
@@ -698,27 +2442,22 @@ var doc = `{
                 }
             }
         },
-        "/api/wf/role-users/_batch": {
+        "/api/wf/tasks/{id}/_read": {
             "post": {
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "role-users"
+                    "tasks"
                 ],
-                "summary": "批量同步(创建或更新)角色用户映射关系",
+                "summary": "抄送标记为已读",
                 "parameters": [
                     {
-                        "description": "request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/request.BatchSyncUserRoleRequest"
-                        }
+                        "type": "integer",
+                        "description": "抄送任务的id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
@@ -796,15 +2535,141 @@ var doc = `{
                     "description": "变量名",
                     "type": "string"
                 },
+                "type": {
+                    "description": "变量类型 number/string/bool/date/decimal/list/object, 为空则根据值推断",
+                    "type": "string"
+                },
                 "value": {
                     "description": "变量值",
                     "type": "object"
                 }
             }
         },
+        "request.AdminJumpRequest": {
+            "type": "object",
+            "properties": {
+                "nodeId": {
+                    "description": "跳转的目标节点id, 只能是用户任务或者结束节点",
+                    "type": "string"
+                },
+                "processInstanceId": {
+                    "description": "流程实例的id",
+                    "type": "integer"
+                },
+                "remarks": {
+                    "description": "备注",
+                    "type": "string"
+                }
+            }
+        },
+        "request.AdminReassignRequest": {
+            "type": "object",
+            "properties": {
+                "fromUser": {
+                    "description": "被替换的处理人, 为空则替换所有未处理的人",
+                    "type": "string"
+                },
+                "nodeId": {
+                    "description": "当前所在节点的id",
+                    "type": "string"
+                },
+                "processInstanceId": {
+                    "description": "流程实例的id",
+                    "type": "integer"
+                },
+                "remarks": {
+                    "description": "备注",
+                    "type": "string"
+                },
+                "toUsers": {
+                    "description": "新的处理人",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "request.AdminTerminateRequest": {
+            "type": "object",
+            "properties": {
+                "processInstanceId": {
+                    "description": "流程实例的id",
+                    "type": "integer"
+                },
+                "remarks": {
+                    "description": "备注",
+                    "type": "string"
+                }
+            }
+        },
+        "request.ApiKeyRequest": {
+            "type": "object",
+            "properties": {
+                "allowImpersonation": {
+                    "description": "是否允许通过WF-CURRENT-USER请求头代替其他用户操作",
+                    "type": "boolean"
+                },
+                "expireTime": {
+                    "description": "过期时间, 为空则不过期",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "名称, 比如调用方的系统名称",
+                    "type": "string"
+                },
+                "userIdentifier": {
+                    "description": "使用该key时的用户标识",
+                    "type": "string"
+                }
+            }
+        },
+        "request.BatchHandleInstancesRequest": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "需要处理的流程实例",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/request.BatchHandleItem"
+                    }
+                },
+                "remarks": {
+                    "description": "备注, item上没有备注的时候使用",
+                    "type": "string"
+                }
+            }
+        },
+        "request.BatchHandleItem": {
+            "type": "object",
+            "properties": {
+                "edgeId": {
+                    "description": "走的流程的id, 为空则走当前节点默认同意的顺序流",
+                    "type": "string"
+                },
+                "isDeny": {
+                    "description": "是否否决, 为true的时候忽略edgeId",
+                    "type": "boolean"
+                },
+                "processInstanceId": {
+                    "description": "流程实例的id",
+                    "type": "integer"
+                },
+                "remarks": {
+                    "description": "备注",
+                    "type": "string"
+                }
+            }
+        },
         "request.BatchSyncUserRoleRequest": {
             "type": "object",
             "properties": {
+                "departments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/request.DepartmentRequest"
+                    }
+                },
                 "roles": {
                     "type": "array",
                     "items": {
@@ -825,6 +2690,26 @@ var doc = `{
                 }
             }
         },
+        "request.ClassifyRequest": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "分类名称",
+                    "type": "string"
+                },
+                "parentId": {
+                    "description": "父分类id, 0为顶级分类",
+                    "type": "integer"
+                },
+                "sort": {
+                    "description": "排序, 越小越靠前",
+                    "type": "integer"
+                }
+            }
+        },
         "request.CloneDefinitionRequest": {
             "type": "object",
             "properties": {
@@ -850,11 +2735,30 @@ var doc = `{
                 }
             }
         },
+        "request.DepartmentRequest": {
+            "type": "object",
+            "properties": {
+                "identifier": {
+                    "type": "string"
+                },
+                "leaderIdentifier": {
+                    "description": "部门负责人的用户id",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parentIdentifier": {
+                    "description": "上级部门的id",
+                    "type": "string"
+                }
+            }
+        },
         "request.HandleInstancesRequest": {
             "type": "object",
             "properties": {
                 "edgeId": {
-                    "description": "走的流程的id",
+                    "description": "走的流程的id, 为空则在当前节点同意的顺序流中根据条件表达式选择",
                     "type": "string"
                 },
                 "processInstanceId": {
@@ -900,6 +2804,20 @@ var doc = `{
                     "description": "流程备注",
                     "type": "string"
                 },
+                "starterRoles": {
+                    "description": "可以发起流程的角色",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "starterUsers": {
+                    "description": "可以发起流程的用户, 和StarterRoles都为空时所有人都可以发起",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "structure": {
                     "description": "流程结构",
                     "type": "string"
@@ -907,6 +2825,24 @@ var doc = `{
                 "task": {
                     "description": "任务ID, array, 可执行多个任务，可以当成通知任务，每个节点都会去执行",
                     "type": "string"
+                },
+                "viewerManagers": {
+                    "description": "发起人的各级上级是否可以查看发起人的流程实例",
+                    "type": "boolean"
+                },
+                "viewerRoles": {
+                    "description": "可以查看该流程所有实例的角色(观察者)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "viewerUsers": {
+                    "description": "可以查看该流程所有实例的用户(观察者)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
@@ -941,12 +2877,48 @@ var doc = `{
                 }
             }
         },
+        "request.TenantRequest": {
+            "type": "object",
+            "properties": {
+                "displayName": {
+                    "description": "租户显示名称",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "locale": {
+                    "description": "语言区域, 比如zh-CN",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "租户编码, 对应请求头中的WF-TENANT-CODE",
+                    "type": "string"
+                },
+                "settings": {
+                    "description": "租户的自定义设置",
+                    "type": "object"
+                },
+                "timeZone": {
+                    "description": "时区, 比如Asia/Shanghai",
+                    "type": "string"
+                }
+            }
+        },
         "request.UserRequest": {
             "type": "object",
             "properties": {
+                "departmentIdentifier": {
+                    "description": "所属部门的id",
+                    "type": "string"
+                },
                 "identifier": {
                     "type": "string"
                 },
+                "managerIdentifier": {
+                    "description": "直属上级的用户id",
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 }
@@ -17,6 +17,14 @@ const (
 	I_ICreated            // 我创建的
 	I_IRelated            // 和我相关的
 	I_All                 // 所有
+	I_IHandled            // 我处理过的
+)
+
+// process instance 的状态
+const (
+	InstanceRunning = iota + 1 // 进行中
+	InstanceEnded              // 已结束
+	InstanceDenied             // 已否决
 )
 
 // process definition 的type类别
@@ -44,3 +52,18 @@ const (
 	HistoryTypeFull = iota + 1
 	HistoryTypeSimple
 )
+
+// 待办任务的状态
+const (
+	TaskPending   = iota + 1 // 待处理
+	TaskCompleted            // 已处理
+	TaskCancelled            // 已取消(其他人已处理或者流程被否决)
+	TaskCcUnread             // 抄送未读
+	TaskCcRead               // 抄送已读
+)
+
+// 任务的类型
+const (
+	TaskKindApproval = iota + 1 // 审批
+	TaskKindCc                  // 抄送
+)
@@ -55,6 +55,8 @@ func Failed(c echo.Context, err error) error {
 		status = http.StatusNotFound
 	case util.Forbidden:
 		status = http.StatusForbidden
+	case util.Conflict:
+		status = http.StatusConflict
 	case util.NoType, util.InternalServerError:
 		status = http.StatusInternalServerError
 	}
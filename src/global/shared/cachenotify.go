@@ -0,0 +1,50 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/17 10:12
+ * @Desc: 多副本之间的缓存失效通知, 基于postgres的LISTEN/NOTIFY
+ */
+package shared
+
+import (
+	"fmt"
+	"strings"
+
+	"workflow/src/global"
+)
+
+// 缓存失效通知的channel名称
+const CacheInvalidationChannel = "wf_cache_invalidation"
+
+// 缓存key
+const TenantsCacheKey = "tenants"
+
+// 流程定义的缓存key, 以分隔符结尾, 按前缀失效的时候definition:1:1不会把definition:1:10也失效
+func DefinitionCacheKey(tenantId int, definitionId int) string {
+	return fmt.Sprintf("definition:%d:%d:", tenantId, definitionId)
+}
+
+// 用户角色的缓存key, 传空的userIdentifier可以得到当前租户所有用户角色缓存的前缀
+func UserRolesCacheKey(tenantId int, userIdentifier string) string {
+	return fmt.Sprintf("roles:%d:%s", tenantId, userIdentifier)
+}
+
+// 使当前副本以及其他副本中以prefix开头的缓存失效
+// 本地立即失效, 其他副本通过pg_notify通知
+// 通知不在调用方的事务中发送, 所以需要在事务提交之后调用, 否则其他副本可能在提交之前重新加载到旧数据
+func InvalidateCache(prefix string) {
+	EvictLocalCache(prefix)
+
+	err := global.BankDb.Exec("select pg_notify(?, ?)", CacheInvalidationChannel, prefix).Error
+	if err != nil {
+		global.BankLogger.Error("发送缓存失效通知失败", err)
+	}
+}
+
+// 使当前副本中以prefix开头的缓存失效
+func EvictLocalCache(prefix string) {
+	for key := range global.BankCache.Items() {
+		if strings.HasPrefix(key, prefix) {
+			global.BankCache.Delete(key)
+		}
+	}
+}
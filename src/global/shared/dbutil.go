@@ -6,13 +6,14 @@
 package shared
 
 import (
-	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"workflow/src/model/request"
-
-	"gorm.io/gorm"
+	"workflow/src/util"
 )
 
+// 分页和排序, 排序字段需要先经过ResolveSort校验
 func ApplyPaging(db *gorm.DB, r *request.PagingRequest) *gorm.DB {
 	// 如果等于0说明没传Limit参数，那么等于-1(不限制)
 	limit := r.Limit
@@ -20,41 +21,30 @@ func ApplyPaging(db *gorm.DB, r *request.PagingRequest) *gorm.DB {
 		limit = -1
 	}
 
-	order := "desc"
-	if r.Order == "asc" {
-		order = r.Order
-	}
-
 	sort := r.Sort
 	if sort == "" {
 		sort = "update_time"
 	}
 
-	return db.Offset(r.Offset).Limit(r.Limit).Order(fmt.Sprintf("%s %s", sort, order))
+	// 排序字段作为标识符引用, 不拼接进sql
+	return db.Offset(r.Offset).Limit(limit).Order(clause.OrderByColumn{
+		Column: clause.Column{Name: sort},
+		Desc:   r.Order != "asc",
+	})
 }
 
-func ApplyRawPaging(sql string, r *request.PagingRequest) string {
-	order := "desc"
-	if r.Order == "asc" {
-		order = r.Order
+// 将请求中的排序键转换成实际的列名
+// sortColumns的key为允许的排序键, value为对应的列名, key为空字符串的是默认排序
+func ResolveSort(r *request.PagingRequest, sortColumns map[string]string) error {
+	column, ok := sortColumns[r.Sort]
+	if !ok {
+		return util.BadRequest.Newf("不支持的排序字段: %s", r.Sort)
 	}
-	sort := r.Sort
-	if sort == "" {
-		sort = "update_time"
-	}
-
-	sql += fmt.Sprintf(" order by %s %s ", sort, order)
+	r.Sort = column
 
-
-	if r.Limit > 0 {
-		sql += fmt.Sprintf(" limit %d ", r.Limit)
+	if r.Order != "" && r.Order != "asc" && r.Order != "desc" {
+		return util.BadRequest.Newf("不支持的排序方式: %s", r.Order)
 	}
 
-	if r.Offset > 0 {
-		sql += fmt.Sprintf(" offset %d ", r.Offset)
-	}
-
-
-
-	return sql
+	return nil
 }
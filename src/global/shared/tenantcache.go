@@ -0,0 +1,112 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/16 14:20
+ * @Desc: 租户缓存
+ */
+package shared
+
+import (
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"workflow/src/global"
+	"workflow/src/model"
+)
+
+// 不存在的租户编码的缓存时间, 避免不存在的租户编码每次请求都查询数据库
+// key以TenantsCacheKey开头, 租户发生变化的时候会一起失效
+const tenantMissTtl = 30 * time.Second
+
+// 从缓存中获取所有的租户信息
+func GetTenants() []model.Tenant {
+	t, succeed := global.BankCache.Get(TenantsCacheKey)
+	if !succeed {
+		return *(UpdateTenantCache())
+	}
+
+	tenants, _ := t.([]model.Tenant)
+
+	return tenants
+}
+
+// 更新新的租户信息到缓存中
+func UpdateTenantCache() *[]model.Tenant {
+	var tenants []model.Tenant
+	global.BankDb.
+		Model(&model.Tenant{}).
+		Find(&tenants)
+
+	global.BankCache.SetDefault(TenantsCacheKey, tenants)
+	global.BankLogger.Infoln("租户缓存更新成功")
+
+	return &tenants
+}
+
+// 根据租户编码获取租户, 缓存中不存在的时候只按编码查询一次数据库, 可能是其他副本刚创建的租户
+// 租户不存在的时候返回nil
+func FindTenant(name string) (*model.Tenant, error) {
+	tenants := GetTenants()
+	for i := range tenants {
+		if tenants[i].Name == name {
+			return &tenants[i], nil
+		}
+	}
+
+	missKey := TenantsCacheKey + ":miss:" + name
+	if _, ok := global.BankCache.Get(missKey); ok {
+		return nil, nil
+	}
+
+	var found []model.Tenant
+	err := global.BankDb.
+		Where("name = ?", name).
+		Limit(1).
+		Find(&found).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		global.BankCache.Set(missKey, true, tenantMissTtl)
+		return nil, nil
+	}
+
+	// 加入本地缓存, 不需要等待其他副本的失效通知
+	global.BankCache.SetDefault(TenantsCacheKey, append(append(make([]model.Tenant, 0, len(tenants)+1), tenants...), found[0]))
+
+	return &found[0], nil
+}
+
+// 租户不存在的时候创建, 多个副本同时创建同一个租户时依赖租户编码的唯一索引只创建一条
+func CreateTenantIfNotExists(name string) (*model.Tenant, error) {
+	now := time.Now().Local()
+	tenant := model.Tenant{
+		Name:       name,
+		CreateTime: now,
+		UpdateTime: now,
+	}
+	result := global.BankDb.
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).
+		Create(&tenant)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	// 其他副本已经创建了的时候重新查询
+	if result.RowsAffected == 0 {
+		tenant = model.Tenant{}
+		err := global.BankDb.
+			Where("name = ?", name).
+			First(&tenant).
+			Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 通知所有副本更新租户缓存
+	InvalidateCache(TenantsCacheKey)
+
+	return &tenant, nil
+}
@@ -0,0 +1,33 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/22 9:30
+ * @Desc: 外部系统同步过来的用户角色
+ */
+package shared
+
+import (
+	"workflow/src/global"
+	"workflow/src/model"
+)
+
+// 获取用户在当前租户下的角色标识, 结果会被缓存, 同步角色用户之后失效
+func GetUserRoleIdentifiers(userIdentifier string, tenantId int) ([]string, error) {
+	cacheKey := UserRolesCacheKey(tenantId, userIdentifier)
+	if cached, ok := global.BankCache.Get(cacheKey); ok {
+		return cached.([]string), nil
+	}
+
+	roles := make([]string, 0)
+	err := global.BankDb.Model(&model.Role{}).
+		Joins("inner join wf.user_role on user_role.role_identifier = role.identifier").
+		Where("role.tenant_id = ? and user_role.user_identifier = ?", tenantId, userIdentifier).
+		Distinct("role.identifier").
+		Scan(&roles).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	global.BankCache.SetDefault(cacheKey, roles)
+
+	return roles, nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/patrickmn/go-cache"
 
 	"workflow/src/global"
+	"workflow/src/global/shared"
 	"workflow/src/model"
 )
 
@@ -27,6 +28,6 @@ func setupCache() {
 		log.Fatalf("初始化租户失败, err:%s", err.Error())
 	}
 
-	c.SetDefault("tenants", tenants)
+	c.SetDefault(shared.TenantsCacheKey, tenants)
 	log.Printf("-------租户列表缓存成功，当前租户个数:%d--------\n", len(tenants))
 }
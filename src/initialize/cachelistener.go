@@ -0,0 +1,68 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/17 10:40
+ * @Desc: 监听其他副本发出的缓存失效通知和流程事件
+ */
+package initialize
+
+import (
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+
+	"workflow/src/global"
+	"workflow/src/global/shared"
+	"workflow/src/service/event"
+)
+
+func setupCacheListener() {
+	listener := pq.NewListener(dbConnString(), 5*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventConnectionAttemptFailed, pq.ListenerEventDisconnected:
+			global.BankLogger.Error("缓存失效通知的监听连接异常", err)
+		case pq.ListenerEventReconnected:
+			// 断线期间可能错过了通知, 清空全部缓存
+			global.BankCache.Flush()
+			global.BankLogger.Infoln("缓存失效通知的监听连接已恢复, 已清空本地缓存")
+		}
+	})
+
+	err := listener.Listen(shared.CacheInvalidationChannel)
+	if err != nil {
+		log.Printf("-------监听缓存失效通知失败, 多副本部署时缓存可能不一致, err:%s--------\n", err.Error())
+	}
+
+	// 流程事件需要推送给连接在其他副本上的订阅者, 监听失败的时候只推送给当前副本
+	err = listener.Listen(event.NotifyChannel)
+	if err != nil {
+		log.Printf("-------监听流程事件通知失败, 多副本部署时其他副本的订阅者收不到事件, err:%s--------\n", err.Error())
+	} else {
+		event.EnableBroadcast()
+	}
+
+	go func() {
+		for {
+			select {
+			case notification := <-listener.Notify:
+				// 重连的时候会收到nil
+				if notification == nil {
+					continue
+				}
+				switch notification.Channel {
+				case event.NotifyChannel:
+					event.HandleNotification(notification.Extra)
+				default:
+					shared.EvictLocalCache(notification.Extra)
+				}
+			case <-time.After(90 * time.Second):
+				// 长时间没有通知的时候检查一下连接是否正常
+				go func() {
+					_ = listener.Ping()
+				}()
+			}
+		}
+	}()
+
+	log.Println("-------开始监听缓存失效通知--------")
+}
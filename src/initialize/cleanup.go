@@ -0,0 +1,31 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/20 11:20
+ * @Desc: 定时清理过期的数据
+ */
+package initialize
+
+import (
+	"time"
+
+	"workflow/src/global"
+	"workflow/src/model"
+)
+
+func setupCleanup() {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			// 过期的幂等记录
+			err := global.BankDb.
+				Where("expire_time <= ?", time.Now().Local()).
+				Delete(&model.IdempotencyRecord{}).
+				Error
+			if err != nil {
+				global.BankLogger.Error("清理过期的幂等记录失败", err)
+			}
+		}
+	}()
+}
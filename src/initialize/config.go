@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"workflow/src/global"
 	"workflow/src/util"
@@ -33,6 +34,16 @@ func setupConfig() {
 
 	// 加载环境变量
 	util.LoadEnv(&global.BankConfig)
+
+	// 条件表达式的执行限制
+	expressionCfg := global.BankConfig.Expression
+	util.SetExpressionLimits(util.ExpressionLimits{
+		MaxLength:       expressionCfg.MaxLength,
+		MaxNodes:        expressionCfg.MaxNodes,
+		MaxRange:        expressionCfg.MaxRange,
+		MaxClosureDepth: expressionCfg.MaxClosureDepth,
+		CacheTtl:        time.Duration(expressionCfg.CacheTtl) * time.Minute,
+	})
 }
 
 func getEnvCode() string {
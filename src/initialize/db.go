@@ -26,11 +26,8 @@ func setupDbConn() {
 	dbCfg := global.BankConfig.Db
 
 	// 初始化数据库连接
-	connStr := fmt.Sprintf("host=%s port=%d user=%s dbname=%s password=%s sslmode=disable TimeZone=Asia/Shanghai",
-		dbCfg.Host, dbCfg.Port, dbCfg.Username, dbCfg.Database, dbCfg.Password)
-
 	db, err := gorm.Open(postgres.New(postgres.Config{
-		DSN:                  connStr,
+		DSN:                  dbConnString(),
 		PreferSimpleProtocol: true,
 	}), &gorm.Config{
 		Logger:                                   logger.Default.LogMode(logger.Info),
@@ -55,6 +52,14 @@ func setupDbConn() {
 	}
 }
 
+// 数据库连接字符串
+func dbConnString() string {
+	dbCfg := global.BankConfig.Db
+
+	return fmt.Sprintf("host=%s port=%d user=%s dbname=%s password=%s sslmode=disable TimeZone=Asia/Shanghai",
+		dbCfg.Host, dbCfg.Port, dbCfg.Username, dbCfg.Database, dbCfg.Password)
+}
+
 // 自动迁移
 func doMigration() {
 	err := global.BankDb.Exec("create schema if not exists wf;").Error
@@ -65,8 +70,11 @@ func doMigration() {
 	err = global.BankDb.AutoMigrate(
 		&model.ProcessDefinition{}, &model.ProcessInstance{},
 		&model.Classify{}, &model.CirculationHistory{},
-		&model.Tenant{}, &model.User{},
-		&model.Role{}, &model.UserRole{})
+		&model.Tenant{}, &model.User{}, &model.ApiKey{},
+		&model.Role{}, &model.UserRole{},
+		&model.ProcessDefinitionVersion{}, &model.Task{},
+		&model.IdempotencyRecord{}, &model.VariableHistory{},
+		&model.Department{})
 	if err != nil {
 		log.Fatalf("迁移表结构发生错误，错误信息为:%s", err.Error())
 	}
@@ -17,4 +17,10 @@ func init() {
 
 	// 内存缓存
 	setupCache()
+
+	// 多副本之间的缓存失效通知
+	setupCacheListener()
+
+	// 定时清理过期数据
+	setupCleanup()
 }
@@ -0,0 +1,33 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/16 14:35
+ * @Desc: 管理接口中间件
+ */
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"workflow/src/global"
+	"workflow/src/global/response"
+)
+
+// 管理接口通过请求头WF-ADMIN-KEY传递密钥, 没有配置密钥的时候不开放
+func AdminKey(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		adminKey := global.BankConfig.App.AdminKey
+		if adminKey == "" {
+			return response.FailWithMsg(c, http.StatusForbidden, "管理接口未开放")
+		}
+
+		key := c.Request().Header.Get("WF-ADMIN-KEY")
+		if subtle.ConstantTimeCompare([]byte(key), []byte(adminKey)) != 1 {
+			return response.FailWithMsg(c, http.StatusUnauthorized, "管理密钥不正确")
+		}
+
+		return next(c)
+	}
+}
@@ -0,0 +1,70 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/18 10:40
+ * @Desc: 租户的api key
+ */
+package auth
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"workflow/src/global"
+	"workflow/src/global/shared"
+	"workflow/src/model"
+	"workflow/src/util"
+)
+
+// 通过WF-API-KEY请求头传递租户的api key, 用于服务之间的调用
+type ApiKeyAuthenticator struct{}
+
+func (a *ApiKeyAuthenticator) Authenticate(c echo.Context) (*Identity, error) {
+	key := c.Request().Header.Get("WF-API-KEY")
+	if key == "" {
+		return nil, nil
+	}
+
+	var apiKey model.ApiKey
+	err := global.BankDb.
+		Where("key_hash = ?", util.Sha256Hex(key)).
+		First(&apiKey).
+		Error
+	if err != nil {
+		return nil, unauthorized("api key不正确")
+	}
+	if apiKey.IsDisabled {
+		return nil, unauthorized("api key已被禁用")
+	}
+	if apiKey.ExpireTime != nil && apiKey.ExpireTime.Before(time.Now()) {
+		return nil, unauthorized("api key已过期")
+	}
+
+	tenantCode := ""
+	for _, tenant := range shared.GetTenants() {
+		if tenant.Id == apiKey.TenantId {
+			tenantCode = tenant.Name
+			break
+		}
+	}
+	if tenantCode == "" {
+		return nil, unauthorized("api key对应的租户不存在")
+	}
+
+	identity := &Identity{
+		UserIdentifier: apiKey.UserIdentifier,
+		TenantCode:     tenantCode,
+	}
+
+	// 允许代替其他用户操作的时候, 优先使用请求头中的用户
+	if apiKey.AllowImpersonation {
+		if currentUser := c.Request().Header.Get("WF-CURRENT-USER"); currentUser != "" {
+			identity.UserIdentifier = currentUser
+		}
+	}
+	if identity.UserIdentifier == "" {
+		return nil, unauthorized("未指定当前用户")
+	}
+
+	return identity, nil
+}
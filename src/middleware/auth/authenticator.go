@@ -0,0 +1,68 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/18 10:20
+ * @Desc: 可插拔的认证方式
+ */
+package auth
+
+import (
+	"log"
+
+	"github.com/labstack/echo/v4"
+
+	"workflow/src/config"
+)
+
+// 认证通过之后的身份
+type Identity struct {
+	UserIdentifier string // 用户标识
+	TenantCode     string // 租户编码, 为空则使用WF-TENANT-CODE请求头
+}
+
+// 认证方式
+type Authenticator interface {
+	// 当前请求不适用该认证方式时返回nil, nil
+	// 适用但是认证失败时返回error
+	Authenticate(c echo.Context) (*Identity, error)
+}
+
+// 根据配置创建认证方式, 按照 api key -> jwt -> 可信网关请求头 的顺序尝试
+// 开启了api key或jwt的时候默认不信任请求头, 否则没有携带token的请求会退回到请求头认证
+func NewAuthenticators(cfg config.Auth) []Authenticator {
+	authenticators := make([]Authenticator, 0)
+
+	if cfg.EnableApiKey {
+		authenticators = append(authenticators, &ApiKeyAuthenticator{})
+	}
+
+	if cfg.Jwt.Enable {
+		jwtAuthenticator, err := NewJwtAuthenticator(cfg.Jwt)
+		if err != nil {
+			log.Fatalf("jwt认证初始化失败, 原因: %s", err.Error())
+		}
+		authenticators = append(authenticators, jwtAuthenticator)
+	}
+
+	if cfg.TrustedGateway {
+		if len(authenticators) == 0 || cfg.AllowMixedMode {
+			authenticators = append(authenticators, &HeaderAuthenticator{})
+		} else {
+			log.Println("开启了api key或jwt认证, 忽略trusted_gateway配置, 如果需要同时信任WF-CURRENT-USER请求头请开启allow_mixed_mode")
+		}
+	}
+
+	return authenticators
+}
+
+// 认证失败
+type authError struct {
+	message string
+}
+
+func (e authError) Error() string {
+	return e.message
+}
+
+func unauthorized(message string) error {
+	return authError{message: message}
+}
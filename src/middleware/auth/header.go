@@ -0,0 +1,22 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/18 10:32
+ * @Desc: 可信网关传递的用户请求头
+ */
+package auth
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+// 直接信任WF-CURRENT-USER请求头, 只能在网关已经完成认证的时候使用
+type HeaderAuthenticator struct{}
+
+func (a *HeaderAuthenticator) Authenticate(c echo.Context) (*Identity, error) {
+	currentUser := c.Request().Header.Get("WF-CURRENT-USER")
+	if currentUser == "" {
+		return nil, nil
+	}
+
+	return &Identity{UserIdentifier: currentUser}, nil
+}
@@ -0,0 +1,199 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/18 11:05
+ * @Desc: jwt的验证密钥, 支持PEM公钥文件、本地JWKS文件和远程JWKS地址
+ */
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"workflow/src/global"
+)
+
+// 验证密钥, Key为*rsa.PublicKey或者[]byte(hmac)
+type verificationKey struct {
+	Kid string
+	Alg string
+	Key interface{}
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	K   string `json:"k"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// 解析JWKS, 只保留RSA和oct(hmac)类型的签名密钥
+func parseJwks(data []byte) ([]verificationKey, error) {
+	var set jsonWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("JWKS格式不正确: %s", err.Error())
+	}
+
+	keys := make([]verificationKey, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		switch k.Kty {
+		case "RSA":
+			n, err := base64.RawURLEncoding.DecodeString(k.N)
+			if err != nil {
+				return nil, fmt.Errorf("JWKS中kid为%s的n不正确", k.Kid)
+			}
+			e, err := base64.RawURLEncoding.DecodeString(k.E)
+			if err != nil {
+				return nil, fmt.Errorf("JWKS中kid为%s的e不正确", k.Kid)
+			}
+			keys = append(keys, verificationKey{
+				Kid: k.Kid,
+				Alg: k.Alg,
+				Key: &rsa.PublicKey{
+					N: new(big.Int).SetBytes(n),
+					E: int(new(big.Int).SetBytes(e).Int64()),
+				},
+			})
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil {
+				return nil, fmt.Errorf("JWKS中kid为%s的k不正确", k.Kid)
+			}
+			keys = append(keys, verificationKey{Kid: k.Kid, Alg: k.Alg, Key: secret})
+		}
+	}
+
+	return keys, nil
+}
+
+// 解析PEM格式的RSA公钥, 支持PKIX公钥、PKCS1公钥和证书
+func parseRsaPublicKeyPem(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("公钥不是PEM格式")
+	}
+
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if key, ok := cert.PublicKey.(*rsa.PublicKey); ok {
+			return key, nil
+		}
+	default:
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if rsaKey, ok := key.(*rsa.PublicKey); ok {
+			return rsaKey, nil
+		}
+	}
+
+	return nil, fmt.Errorf("公钥不是RSA公钥")
+}
+
+// 远程的JWKS, 定期刷新, 遇到未知的kid时也会提前刷新(最多一分钟一次)
+// 请求JWKS地址的时候不持有锁, 其他请求继续使用当前的公钥, 不会因为JWKS地址变慢而排队
+type remoteKeySet struct {
+	url             string
+	refreshInterval time.Duration
+	client          *http.Client
+
+	mu          sync.Mutex
+	keys        []verificationKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	fetching    chan struct{} // 正在刷新的时候不为nil, 刷新结束之后关闭
+}
+
+func newRemoteKeySet(url string, refreshInterval time.Duration) *remoteKeySet {
+	return &remoteKeySet{
+		url:             url,
+		refreshInterval: refreshInterval,
+		client:          &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *remoteKeySet) Keys(forceRefresh bool) []verificationKey {
+	s.mu.Lock()
+	now := time.Now()
+	expired := now.Sub(s.fetchedAt) > s.refreshInterval
+	if s.fetching == nil && (expired || forceRefresh) && now.Sub(s.attemptedAt) > time.Minute {
+		s.attemptedAt = now
+		done := make(chan struct{})
+		s.fetching = done
+		s.mu.Unlock()
+
+		keys, err := s.fetch()
+
+		s.mu.Lock()
+		if err != nil {
+			global.BankLogger.Error("获取JWKS失败", err)
+		} else {
+			s.keys = keys
+			s.fetchedAt = now
+		}
+		s.fetching = nil
+		close(done)
+		keys = s.keys
+		s.mu.Unlock()
+
+		return keys
+	}
+
+	keys, fetching := s.keys, s.fetching
+	s.mu.Unlock()
+
+	// 还没有任何公钥的时候(比如刚启动)等待正在进行的刷新
+	if len(keys) == 0 && fetching != nil {
+		<-fetching
+		s.mu.Lock()
+		keys = s.keys
+		s.mu.Unlock()
+	}
+
+	return keys
+}
+
+func (s *remoteKeySet) fetch() ([]verificationKey, error) {
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS地址返回了%d", resp.StatusCode)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseJwks(data)
+}
@@ -0,0 +1,294 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/18 11:40
+ * @Desc: jwt认证, 支持HS256/HS384/HS512和RS256/RS384/RS512
+ */
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"workflow/src/config"
+)
+
+// 支持的签名算法
+var jwtAlgorithms = map[string]crypto.Hash{
+	"HS256": crypto.SHA256,
+	"HS384": crypto.SHA384,
+	"HS512": crypto.SHA512,
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+}
+
+// 通过Authorization: Bearer <token>请求头传递的jwt
+type JwtAuthenticator struct {
+	cfg    config.Jwt
+	keys   []verificationKey // 配置的hmac密钥、PEM公钥和本地JWKS
+	remote *remoteKeySet     // 远程JWKS
+	now    func() time.Time
+}
+
+func NewJwtAuthenticator(cfg config.Jwt) (*JwtAuthenticator, error) {
+	a := &JwtAuthenticator{
+		cfg:  cfg,
+		keys: make([]verificationKey, 0),
+		now:  time.Now,
+	}
+
+	if cfg.HmacSecret != "" {
+		a.keys = append(a.keys, verificationKey{Key: []byte(cfg.HmacSecret)})
+	}
+
+	if cfg.PublicKeyFile != "" {
+		data, err := ioutil.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		key, err := parseRsaPublicKeyPem(data)
+		if err != nil {
+			return nil, err
+		}
+		a.keys = append(a.keys, verificationKey{Key: key})
+	}
+
+	if cfg.JwksFile != "" {
+		data, err := ioutil.ReadFile(cfg.JwksFile)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := parseJwks(data)
+		if err != nil {
+			return nil, err
+		}
+		a.keys = append(a.keys, keys...)
+	}
+
+	if cfg.JwksUrl != "" {
+		interval := time.Duration(cfg.JwksRefreshInterval) * time.Minute
+		if interval <= 0 {
+			interval = time.Hour
+		}
+		a.remote = newRemoteKeySet(cfg.JwksUrl, interval)
+	}
+
+	if len(a.keys) == 0 && a.remote == nil {
+		return nil, fmt.Errorf("开启了jwt认证, 但是没有配置任何验证密钥")
+	}
+	if cfg.UserClaim == "" {
+		a.cfg.UserClaim = "sub"
+	}
+
+	return a, nil
+}
+
+func (a *JwtAuthenticator) Authenticate(c echo.Context) (*Identity, error) {
+	authorization := c.Request().Header.Get(echo.HeaderAuthorization)
+	if !strings.HasPrefix(authorization, "Bearer ") {
+		return nil, nil
+	}
+
+	claims, err := a.Verify(strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer ")))
+	if err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		UserIdentifier: claimString(claims, a.cfg.UserClaim),
+	}
+	if identity.UserIdentifier == "" {
+		return nil, unauthorized(fmt.Sprintf("token中缺少用户标识(%s)", a.cfg.UserClaim))
+	}
+	if a.cfg.TenantClaim != "" {
+		identity.TenantCode = claimString(claims, a.cfg.TenantClaim)
+		if identity.TenantCode == "" {
+			return nil, unauthorized(fmt.Sprintf("token中缺少租户编码(%s)", a.cfg.TenantClaim))
+		}
+	}
+
+	return identity, nil
+}
+
+// 验证token的签名和exp/nbf/iss/aud, 返回claims
+func (a *JwtAuthenticator) Verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, unauthorized("token格式不正确")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, unauthorized("token头部不正确")
+	}
+	hash, ok := jwtAlgorithms[header.Alg]
+	if !ok {
+		return nil, unauthorized(fmt.Sprintf("不支持的签名算法: %s", header.Alg))
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, unauthorized("token签名不正确")
+	}
+
+	signingInput := []byte(parts[0] + "." + parts[1])
+	if !a.verifySignature(header.Alg, header.Kid, hash, signingInput, signature) {
+		return nil, unauthorized("token签名验证失败")
+	}
+
+	claims := make(map[string]interface{})
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, unauthorized("token内容不正确")
+	}
+
+	if err := a.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (a *JwtAuthenticator) verifySignature(alg string, kid string, hash crypto.Hash, signingInput []byte, signature []byte) bool {
+	if a.verifyWithKeys(a.keys, alg, kid, hash, signingInput, signature) {
+		return true
+	}
+
+	if a.remote == nil {
+		return false
+	}
+	if a.verifyWithKeys(a.remote.Keys(false), alg, kid, hash, signingInput, signature) {
+		return true
+	}
+
+	// 可能是密钥轮换了, 刷新之后再试一次
+	return a.verifyWithKeys(a.remote.Keys(true), alg, kid, hash, signingInput, signature)
+}
+
+func (a *JwtAuthenticator) verifyWithKeys(keys []verificationKey, alg string, kid string, hash crypto.Hash, signingInput []byte, signature []byte) bool {
+	for _, key := range keys {
+		if kid != "" && key.Kid != "" && key.Kid != kid {
+			continue
+		}
+		if key.Alg != "" && key.Alg != alg {
+			continue
+		}
+
+		// 算法和密钥类型必须匹配, 避免用rsa公钥当作hmac密钥
+		switch k := key.Key.(type) {
+		case []byte:
+			if !strings.HasPrefix(alg, "HS") {
+				continue
+			}
+			mac := hmac.New(hash.New, k)
+			mac.Write(signingInput)
+			if hmac.Equal(mac.Sum(nil), signature) {
+				return true
+			}
+		case *rsa.PublicKey:
+			if !strings.HasPrefix(alg, "RS") {
+				continue
+			}
+			hasher := hash.New()
+			hasher.Write(signingInput)
+			if rsa.VerifyPKCS1v15(k, hash, hasher.Sum(nil), signature) == nil {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (a *JwtAuthenticator) validateClaims(claims map[string]interface{}) error {
+	now := a.now()
+	leeway := time.Duration(a.cfg.Leeway) * time.Second
+
+	// 必须有exp, 否则token会永久有效
+	exp, ok := claimTime(claims, "exp")
+	if !ok {
+		return unauthorized("token中缺少过期时间(exp)")
+	}
+	if now.After(exp.Add(leeway)) {
+		return unauthorized("token已过期")
+	}
+	if nbf, ok := claimTime(claims, "nbf"); ok && now.Add(leeway).Before(nbf) {
+		return unauthorized("token尚未生效")
+	}
+
+	if a.cfg.Issuer != "" && claimString(claims, "iss") != a.cfg.Issuer {
+		return unauthorized("token的签发者不正确")
+	}
+
+	if a.cfg.Audience != "" {
+		matched := false
+		switch aud := claims["aud"].(type) {
+		case string:
+			matched = aud == a.cfg.Audience
+		case []interface{}:
+			for _, item := range aud {
+				if s, ok := item.(string); ok && s == a.cfg.Audience {
+					matched = true
+					break
+				}
+			}
+		}
+		if !matched {
+			return unauthorized("token的受众不正确")
+		}
+	}
+
+	return nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(v)
+}
+
+// claim转成字符串, 数字类型的用户id也可以使用
+func claimString(claims map[string]interface{}, name string) string {
+	switch v := claims[name].(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return ""
+	}
+}
+
+func claimTime(claims map[string]interface{}, name string) (time.Time, bool) {
+	number, ok := claims[name].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(int64(seconds), 0), true
+}
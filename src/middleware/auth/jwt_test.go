@@ -0,0 +1,330 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/18 15:20
+ * @Desc: jwt认证的测试, 密钥都在测试中生成
+ */
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"workflow/src/config"
+)
+
+var testNow = time.Date(2021, 4, 18, 12, 0, 0, 0, time.UTC)
+
+func generateRsaKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("生成rsa密钥失败: %v", err)
+	}
+
+	return key
+}
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// 用hmac密钥签名
+func signHmac(t *testing.T, alg string, kid string, secret []byte, claims map[string]interface{}) string {
+	t.Helper()
+
+	signingInput := encodeSegment(t, map[string]string{"alg": alg, "typ": "JWT", "kid": kid}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(jwtAlgorithms[alg].New, secret)
+	mac.Write([]byte(signingInput))
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// 用rsa私钥签名
+func signRsa(t *testing.T, alg string, kid string, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	t.Helper()
+
+	hash := jwtAlgorithms[alg]
+	signingInput := encodeSegment(t, map[string]string{"alg": alg, "typ": "JWT", "kid": kid}) + "." + encodeSegment(t, claims)
+	hasher := hash.New()
+	hasher.Write([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, hash, hasher.Sum(nil))
+	if err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// 生成包含rsa公钥和hmac密钥的本地JWKS
+func buildJwks(t *testing.T, kid string, key *rsa.PublicKey, hmacKid string, secret []byte) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(jsonWebKeySet{Keys: []jsonWebKey{
+		{
+			Kty: "RSA",
+			Kid: kid,
+			Alg: "RS256",
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		},
+		{
+			Kty: "oct",
+			Kid: hmacKid,
+			K:   base64.RawURLEncoding.EncodeToString(secret),
+		},
+		{
+			Kty: "RSA",
+			Kid: "encryption-key",
+			Use: "enc",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		},
+	}})
+	if err != nil {
+		t.Fatalf("序列化JWKS失败: %v", err)
+	}
+
+	return data
+}
+
+func newTestAuthenticator(cfg config.Jwt, keys ...verificationKey) *JwtAuthenticator {
+	if cfg.UserClaim == "" {
+		cfg.UserClaim = "sub"
+	}
+
+	return &JwtAuthenticator{
+		cfg:  cfg,
+		keys: keys,
+		now:  func() time.Time { return testNow },
+	}
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub": "user-1",
+		"exp": testNow.Add(time.Hour).Unix(),
+	}
+}
+
+func TestParseJwks(t *testing.T) {
+	key := generateRsaKey(t)
+	secret := []byte("jwks-hmac-secret")
+
+	keys, err := parseJwks(buildJwks(t, "rsa-1", &key.PublicKey, "hmac-1", secret))
+	if err != nil {
+		t.Fatalf("解析JWKS失败: %v", err)
+	}
+
+	// use为enc的密钥会被忽略
+	if len(keys) != 2 {
+		t.Fatalf("期望解析出2个密钥, 实际为%d", len(keys))
+	}
+
+	rsaKey, ok := keys[0].Key.(*rsa.PublicKey)
+	if !ok || keys[0].Kid != "rsa-1" || keys[0].Alg != "RS256" {
+		t.Fatalf("rsa密钥解析不正确: %+v", keys[0])
+	}
+	if rsaKey.N.Cmp(key.N) != 0 || rsaKey.E != key.E {
+		t.Fatalf("rsa公钥和生成的不一致")
+	}
+
+	hmacKey, ok := keys[1].Key.([]byte)
+	if !ok || keys[1].Kid != "hmac-1" || string(hmacKey) != string(secret) {
+		t.Fatalf("hmac密钥解析不正确: %+v", keys[1])
+	}
+
+	if _, err := parseJwks([]byte("not json")); err == nil {
+		t.Fatalf("不合法的JWKS应该返回错误")
+	}
+	if _, err := parseJwks([]byte(`{"keys":[{"kty":"RSA","kid":"bad","n":"***","e":"AQAB"}]}`)); err == nil {
+		t.Fatalf("n不合法的JWKS应该返回错误")
+	}
+}
+
+func TestVerifyWithJwks(t *testing.T) {
+	key := generateRsaKey(t)
+	otherKey := generateRsaKey(t)
+	secret := []byte("jwks-hmac-secret")
+
+	keys, err := parseJwks(buildJwks(t, "rsa-1", &key.PublicKey, "hmac-1", secret))
+	if err != nil {
+		t.Fatalf("解析JWKS失败: %v", err)
+	}
+	a := newTestAuthenticator(config.Jwt{}, keys...)
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"rsa签名", signRsa(t, "RS256", "rsa-1", key, validClaims()), true},
+		{"不带kid的rsa签名", signRsa(t, "RS256", "", key, validClaims()), true},
+		{"hmac签名", signHmac(t, "HS256", "hmac-1", secret, validClaims()), true},
+		{"其他私钥的签名", signRsa(t, "RS256", "rsa-1", otherKey, validClaims()), false},
+		{"kid不匹配", signRsa(t, "RS256", "rsa-2", key, validClaims()), false},
+		{"和JWKS中alg不一致", signRsa(t, "RS512", "rsa-1", key, validClaims()), false},
+		{"错误的hmac密钥", signHmac(t, "HS256", "hmac-1", []byte("wrong"), validClaims()), false},
+		{"不支持的算法", "eyJhbGciOiJub25lIn0.eyJzdWIiOiJ1c2VyLTEifQ.", false},
+		{"格式不正确", "abc.def", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := a.Verify(tt.token)
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("期望验证通过, 实际错误: %v", err)
+				}
+				if claimString(claims, "sub") != "user-1" {
+					t.Fatalf("claims不正确: %v", claims)
+				}
+			} else if err == nil {
+				t.Fatalf("期望验证失败")
+			}
+		})
+	}
+}
+
+// 算法和密钥类型必须匹配, 不能用rsa公钥当作hmac密钥伪造token
+func TestVerifyAlgorithmKeyTypeMismatch(t *testing.T) {
+	key := generateRsaKey(t)
+	a := newTestAuthenticator(config.Jwt{}, verificationKey{Key: &key.PublicKey})
+
+	// 攻击者拿到公钥之后, 用公钥的字节作为hmac密钥签名
+	publicKeyBytes := key.PublicKey.N.Bytes()
+	forged := signHmac(t, "HS256", "", publicKeyBytes, validClaims())
+	if _, err := a.Verify(forged); err == nil {
+		t.Fatalf("用rsa公钥作为hmac密钥签名的token不应该验证通过")
+	}
+
+	// hmac密钥不能验证rsa签名
+	secret := []byte("hmac-secret")
+	hmacOnly := newTestAuthenticator(config.Jwt{}, verificationKey{Key: secret})
+	if _, err := hmacOnly.Verify(signRsa(t, "RS256", "", key, validClaims())); err == nil {
+		t.Fatalf("hmac密钥不应该验证通过rsa签名的token")
+	}
+
+	// 同一个算法族内不同的hash算法也可以使用
+	if _, err := hmacOnly.Verify(signHmac(t, "HS512", "", secret, validClaims())); err != nil {
+		t.Fatalf("HS512签名的token应该验证通过: %v", err)
+	}
+	if _, err := a.Verify(signRsa(t, "RS384", "", key, validClaims())); err != nil {
+		t.Fatalf("RS384签名的token应该验证通过: %v", err)
+	}
+}
+
+func TestValidateClaims(t *testing.T) {
+	secret := []byte("hmac-secret")
+	leeway := 60 * time.Second
+
+	tests := []struct {
+		name   string
+		cfg    config.Jwt
+		claims map[string]interface{}
+		valid  bool
+	}{
+		{"正常", config.Jwt{}, validClaims(), true},
+		{"缺少exp", config.Jwt{}, map[string]interface{}{"sub": "user-1"}, false},
+		{"exp不是数字", config.Jwt{}, map[string]interface{}{"sub": "user-1", "exp": "tomorrow"}, false},
+		{"已过期", config.Jwt{}, map[string]interface{}{"sub": "user-1", "exp": testNow.Add(-time.Minute).Unix()}, false},
+		{"过期但是在允许的时钟偏差内", config.Jwt{Leeway: 60}, map[string]interface{}{"sub": "user-1", "exp": testNow.Add(-leeway / 2).Unix()}, true},
+		{"过期超过了允许的时钟偏差", config.Jwt{Leeway: 60}, map[string]interface{}{"sub": "user-1", "exp": testNow.Add(-2 * leeway).Unix()}, false},
+		{"尚未生效", config.Jwt{}, map[string]interface{}{"sub": "user-1", "exp": testNow.Add(time.Hour).Unix(), "nbf": testNow.Add(time.Minute).Unix()}, false},
+		{"nbf在允许的时钟偏差内", config.Jwt{Leeway: 60}, map[string]interface{}{"sub": "user-1", "exp": testNow.Add(time.Hour).Unix(), "nbf": testNow.Add(leeway / 2).Unix()}, true},
+		{"已生效", config.Jwt{}, map[string]interface{}{"sub": "user-1", "exp": testNow.Add(time.Hour).Unix(), "nbf": testNow.Add(-time.Minute).Unix()}, true},
+		{"iss正确", config.Jwt{Issuer: "https://idp"}, withClaim(validClaims(), "iss", "https://idp"), true},
+		{"iss不正确", config.Jwt{Issuer: "https://idp"}, withClaim(validClaims(), "iss", "https://other"), false},
+		{"缺少iss", config.Jwt{Issuer: "https://idp"}, validClaims(), false},
+		{"aud字符串正确", config.Jwt{Audience: "workflow"}, withClaim(validClaims(), "aud", "workflow"), true},
+		{"aud数组包含", config.Jwt{Audience: "workflow"}, withClaim(validClaims(), "aud", []string{"other", "workflow"}), true},
+		{"aud数组不包含", config.Jwt{Audience: "workflow"}, withClaim(validClaims(), "aud", []string{"other"}), false},
+		{"缺少aud", config.Jwt{Audience: "workflow"}, validClaims(), false},
+		{"没有配置aud的时候不校验", config.Jwt{}, withClaim(validClaims(), "aud", "other"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAuthenticator(tt.cfg, verificationKey{Key: secret})
+			_, err := a.Verify(signHmac(t, "HS256", "", secret, tt.claims))
+			if tt.valid && err != nil {
+				t.Fatalf("期望验证通过, 实际错误: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatalf("期望验证失败")
+			}
+		})
+	}
+}
+
+func withClaim(claims map[string]interface{}, name string, value interface{}) map[string]interface{} {
+	claims[name] = value
+	return claims
+}
+
+// 确保测试中使用的hash算法都已经注册
+func TestJwtAlgorithmsAvailable(t *testing.T) {
+	for alg, hash := range jwtAlgorithms {
+		if !hash.Available() {
+			t.Fatalf("%s使用的hash算法%v不可用", alg, hash)
+		}
+	}
+}
+
+func TestRemoteKeySetRefreshDoesNotBlock(t *testing.T) {
+	key := generateRsaKey(t)
+	jwks := buildJwks(t, "rsa-1", &key.PublicKey, "hmac-1", []byte("jwks-hmac-secret"))
+
+	requested := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requested)
+		<-release
+		_, _ = w.Write(jwks)
+	}))
+	defer server.Close()
+
+	oldKeys, err := parseJwks(buildJwks(t, "old", &key.PublicKey, "old-hmac", []byte("old-secret")))
+	if err != nil {
+		t.Fatalf("解析JWKS失败: %v", err)
+	}
+	s := newRemoteKeySet(server.URL, time.Hour)
+	s.keys = oldKeys
+
+	refreshed := make(chan []verificationKey)
+	go func() { refreshed <- s.Keys(false) }()
+	<-requested
+
+	// 刷新还没有结束, 其他请求直接使用旧的公钥
+	done := make(chan []verificationKey)
+	go func() { done <- s.Keys(false) }()
+	select {
+	case keys := <-done:
+		if len(keys) != len(oldKeys) || keys[0].Kid != "old" {
+			t.Fatalf("刷新期间应该返回旧的公钥, 实际为: %+v", keys)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("刷新JWKS的时候其他请求被阻塞了")
+	}
+
+	close(release)
+	if keys := <-refreshed; len(keys) == 0 || keys[0].Kid != "rsa-1" {
+		t.Fatalf("刷新之后应该返回新的公钥, 实际为: %+v", keys)
+	}
+}
@@ -7,27 +7,45 @@ package middleware
 
 import (
 	"net/http"
+	"sync"
 
 	"github.com/labstack/echo/v4"
 
+	"workflow/src/global"
 	"workflow/src/global/response"
-	"workflow/src/util"
+	"workflow/src/middleware/auth"
 )
 
-// 先使用此种方式传递当前用户的标识id
+var (
+	authenticators     []auth.Authenticator
+	authenticatorsOnce sync.Once
+)
+
+// 按照配置的认证方式依次尝试, 第一个适用的认证方式决定认证结果
+// 需要在MultiTenant之前执行, 认证方式中带有租户的时候(jwt的租户claim、api key)会覆盖WF-TENANT-CODE请求头
 func Auth(next echo.HandlerFunc) echo.HandlerFunc {
+	authenticatorsOnce.Do(func() {
+		authenticators = auth.NewAuthenticators(global.BankConfig.Auth)
+	})
+
 	return func(c echo.Context) error {
-		currentUserId := c.Request().Header.Get("WF-CURRENT-USER")
-		if currentUserId == "" {
-			return response.FailWithMsg(c, http.StatusUnauthorized, "未指定当前用户")
-		}
+		for _, authenticator := range authenticators {
+			identity, err := authenticator.Authenticate(c)
+			if err != nil {
+				return response.FailWithMsg(c, http.StatusUnauthorized, err.Error())
+			}
+			if identity == nil {
+				continue
+			}
 
-		if util.StringToUint(currentUserId) == 0 {
-			return response.FailWithMsg(c, http.StatusUnauthorized, "指定的当前用户不合法")
-		}
+			c.Set("currentUser", identity.UserIdentifier)
+			if identity.TenantCode != "" {
+				c.Set("authTenantCode", identity.TenantCode)
+			}
 
-		c.Set("currentUser", currentUserId)
+			return next(c)
+		}
 
-		return next(c)
+		return response.FailWithMsg(c, http.StatusUnauthorized, "未指定当前用户")
 	}
 }
@@ -0,0 +1,221 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/20 10:30
+ * @Desc: 幂等中间件
+ */
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"workflow/src/global"
+	"workflow/src/global/response"
+	"workflow/src/model"
+	"workflow/src/util"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// 请求头带有Idempotency-Key的修改类请求(POST/PUT/PATCH/DELETE), 在有效期内相同租户+用户+key的重试直接返回第一次的响应
+// 需要在Auth和MultiTenant之后执行
+// 1. 第一次请求还在处理中的时候返回409
+// 2. 相同的key用于不同的请求(方法、路径、请求体不同)的时候返回400
+// 3. 第一次请求返回5xx或者409(乐观锁冲突)的时候不保存, 重试时会重新处理
+// 4. 第一次请求处理成功但是保存响应失败的时候保留处理中的记录, 有效期内的重试返回409, 避免重复处理
+func Idempotency(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		key := c.Request().Header.Get("Idempotency-Key")
+		if key == "" || !isMutatingMethod(c.Request().Method) {
+			return next(c)
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return response.BadRequestWithMessage(c, "Idempotency-Key的长度不能超过255")
+		}
+
+		requestHash, err := hashRequest(c)
+		if err != nil {
+			return response.BadRequest(c)
+		}
+
+		tenantId, userIdentifier := util.GetWorkContext(c)
+		record := model.IdempotencyRecord{
+			TenantId:       tenantId,
+			UserIdentifier: userIdentifier,
+			Key:            key,
+			RequestHash:    requestHash,
+			CreateTime:     time.Now().Local(),
+			ExpireTime:     time.Now().Local().Add(time.Duration(global.BankConfig.App.IdempotencyTtl) * time.Minute),
+		}
+
+		created, err := acquireIdempotencyRecord(&record)
+		if err != nil {
+			global.BankLogger.Error("保存幂等记录失败", err)
+			return response.FailWithMsg(c, http.StatusInternalServerError, "保存幂等记录失败")
+		}
+		if !created {
+			return replayIdempotentResponse(c, &record, requestHash)
+		}
+
+		// 处理失败或者panic的时候删除处理中的记录, 重试时会重新处理
+		// panic的时候defer同样会执行, 之后panic继续交给外层的Recover中间件处理
+		handled := false
+		defer func() {
+			if !handled {
+				global.BankDb.Delete(&model.IdempotencyRecord{}, record.Id)
+			}
+		}()
+
+		// 记录下第一次请求的响应
+		writer := &bodyCaptureWriter{ResponseWriter: c.Response().Writer}
+		c.Response().Writer = writer
+
+		err = next(c)
+		status := c.Response().Status
+		if err != nil || status >= http.StatusInternalServerError || status == http.StatusConflict {
+			return err
+		}
+
+		// 已经处理成功, 之后无论响应是否保存成功都不能删除记录, 否则重试会重复处理
+		handled = true
+		err = saveIdempotentResponse(record.Id, status, c.Response().Header().Get(echo.HeaderContentType), writer.body.Bytes())
+		if err != nil {
+			global.BankLogger.Error("保存幂等记录的响应失败, 记录保持处理中直到过期", err)
+		}
+
+		return nil
+	}
+}
+
+// 保存第一次请求的响应, 失败的时候重试
+func saveIdempotentResponse(recordId int, status int, contentType string, body []byte) error {
+	var err error
+	for i := 0; i < 3; i++ {
+		err = global.BankDb.
+			Model(&model.IdempotencyRecord{}).
+			Where("id = ?", recordId).
+			Updates(map[string]interface{}{
+				"is_completed":  true,
+				"status_code":   status,
+				"content_type":  contentType,
+				"response_body": body,
+			}).Error
+		if err == nil {
+			return nil
+		}
+		time.Sleep(time.Duration(i+1) * 100 * time.Millisecond)
+	}
+
+	return err
+}
+
+// 保存一条处理中的幂等记录, 已经存在未过期的记录时返回false并把已存在的记录赋值给record
+func acquireIdempotencyRecord(record *model.IdempotencyRecord) (bool, error) {
+	// 过期的记录当作不存在
+	err := global.BankDb.
+		Where("tenant_id = ?", record.TenantId).
+		Where("user_identifier = ?", record.UserIdentifier).
+		Where("key = ?", record.Key).
+		Where("expire_time <= ?", time.Now().Local()).
+		Delete(&model.IdempotencyRecord{}).
+		Error
+	if err != nil {
+		return false, err
+	}
+
+	// 依赖唯一索引保证并发的相同请求只有一个能保存成功
+	result := global.BankDb.
+		Exec("insert into wf.idempotency_record (tenant_id, user_identifier, key, request_hash, create_time, expire_time) values (?, ?, ?, ?, ?, ?) on conflict do nothing",
+			record.TenantId, record.UserIdentifier, record.Key, record.RequestHash, record.CreateTime, record.ExpireTime)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	err = global.BankDb.
+		Where("tenant_id = ?", record.TenantId).
+		Where("user_identifier = ?", record.UserIdentifier).
+		Where("key = ?", record.Key).
+		First(record).
+		Error
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// 返回第一次请求的响应
+func replayIdempotentResponse(c echo.Context, record *model.IdempotencyRecord, requestHash string) error {
+	if record.RequestHash != requestHash {
+		return response.BadRequestWithMessage(c, "当前Idempotency-Key已经被其他请求使用")
+	}
+	if !record.IsCompleted {
+		return response.FailWithMsg(c, http.StatusConflict, "相同Idempotency-Key的请求正在处理中")
+	}
+
+	c.Response().Header().Set("Idempotent-Replayed", "true")
+	contentType := record.ContentType
+	if contentType == "" {
+		contentType = echo.MIMEApplicationJSONCharsetUTF8
+	}
+
+	return c.Blob(record.StatusCode, contentType, record.ResponseBody)
+}
+
+// 请求方法+路径+请求体的sha256
+func hashRequest(c echo.Context) (string, error) {
+	req := c.Request()
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		if err != nil {
+			return "", err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	return util.Sha256Hex(req.Method + " " + req.URL.RequestURI() + "\n" + string(body)), nil
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+// 在写入响应的同时记录响应内容
+type bodyCaptureWriter struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyCaptureWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *bodyCaptureWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+
+	return nil, nil, errors.New("当前的ResponseWriter不支持Hijack")
+}
@@ -6,79 +6,58 @@
 package middleware
 
 import (
-	"encoding/json"
 	"net/http"
-	"time"
 
-	. "github.com/ahmetb/go-linq/v3"
 	"github.com/labstack/echo/v4"
 
 	"workflow/src/global"
 	"workflow/src/global/response"
-	"workflow/src/model"
-	"workflow/src/util"
+	"workflow/src/global/shared"
 )
 
 func MultiTenant(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		tenantCode := c.Request().Header.Get("WF-TENANT-CODE")
+
+		// 认证信息中带有租户的时候以认证信息为准, 请求头只能和它一致
+		if authTenantCode, ok := c.Get("authTenantCode").(string); ok {
+			if tenantCode != "" && tenantCode != authTenantCode {
+				return response.FailWithMsg(c, http.StatusForbidden, "请求头中的租户和认证信息中的租户不一致")
+			}
+			tenantCode = authTenantCode
+		}
+
 		if tenantCode == "" {
 			return response.FailWithMsg(c, http.StatusUnauthorized, "未指定当前租户")
 		}
 
-		// 从内存缓存中获取全部的tenant
-		tenants := GetTenants()
-
-		tenant := From(tenants).WhereT(func(i model.Tenant) bool {
-			return i.Name == tenantCode
-		}).First()
+		// 优先从内存缓存中获取, 不存在的时候按租户编码查询数据库
+		tenant, err := shared.FindTenant(tenantCode)
+		if err != nil {
+			global.BankLogger.Error("查询租户失败", err)
+			return response.FailWithMsg(c, http.StatusInternalServerError, "查询租户失败")
+		}
 
-		// 不存在新增并更新全部租户缓存
+		// 不存在新增并通知所有副本更新租户缓存
 		if tenant == nil {
-			t := model.Tenant{
-				Name:       tenantCode,
-				CreateTime: time.Now().Local(),
+			if !global.BankConfig.App.AutoCreateTenant {
+				return response.FailWithMsg(c, http.StatusUnauthorized, "指定的租户不存在")
 			}
-			err := global.BankDb.Model(&model.Tenant{}).Create(&t).Error
+
+			tenant, err = shared.CreateTenantIfNotExists(tenantCode)
 			if err != nil {
+				global.BankLogger.Error("创建租户失败", err)
 				return response.FailWithMsg(c, http.StatusUnauthorized, "指定租户失败")
 			}
-			tenant = t
+		}
 
-			// 更新缓存
-			go UpdateTenantCache()
+		if tenant.IsDisabled {
+			return response.FailWithMsg(c, http.StatusForbidden, "当前租户已被禁用")
 		}
 
 		// 当前租户放进缓存
-		c.Set("currentTenant", tenant)
+		c.Set("currentTenant", *tenant)
 
 		return next(c)
 	}
 }
-
-// 从缓存中获取所有的租户信息
-func GetTenants() []model.Tenant {
-	var tenants []model.Tenant
-
-	t, succeed := global.BankCache.Get("tenants")
-	if !succeed {
-		tenants = *(UpdateTenantCache())
-	}
-	bytes := util.MarshalToBytes(t)
-	_ = json.Unmarshal(bytes, &tenants)
-
-	return tenants
-}
-
-// 更新新的租户信息到缓存中
-func UpdateTenantCache() *[]model.Tenant {
-	var tenants []model.Tenant
-	global.BankDb.
-		Model(&model.Tenant{}).
-		Find(&tenants)
-
-	global.BankCache.SetDefault("tenants", tenants)
-	global.BankLogger.Infoln("租户缓存更新成功")
-
-	return &tenants
-}
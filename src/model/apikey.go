@@ -0,0 +1,21 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/18 10:05
+ * @Desc: 租户的api key, 用于服务之间的调用
+ */
+package model
+
+import "time"
+
+type ApiKey struct {
+	EntityBase
+	Name               string     `json:"name"`                                    // 名称, 比如调用方的系统名称
+	Prefix             string     `json:"prefix"`                                  // key的前几位, 用于识别是哪个key
+	KeyHash            string     `gorm:"uniqueIndex" json:"-"`                    // key的sha256, 不保存明文
+	UserIdentifier     string     `json:"userIdentifier"`                          // 使用该key时的用户标识
+	AllowImpersonation bool       `gorm:"default:false" json:"allowImpersonation"` // 是否允许通过WF-CURRENT-USER请求头代替其他用户操作
+	IsDisabled         bool       `gorm:"default:false" json:"isDisabled"`         // 是否已禁用
+	ExpireTime         *time.Time `gorm:"type:timestamp" json:"expireTime"`        // 过期时间, 为空则不过期
+	TenantId           int        `gorm:"index" json:"tenantId"`                   // 租户id
+	CreateTime         time.Time  `gorm:"default:now();type:timestamp" json:"createTime"`
+}
@@ -17,4 +17,5 @@ type CirculationHistory struct {
 	ProcessorId       string `gorm:"index" json:"processorId" form:"processorId"` // 处理人外部系统ID
 	CostDuration      string `json:"costDuration" form:"costDuration"`            // 本条记录的处理时长(每次有新的一条的时候更新这个字段)
 	Remarks           string `json:"remarks" form:"remarks"`                      // 备注
+	IsAdmin           bool   `gorm:"default:false" json:"isAdmin" form:"isAdmin"` // 是否是管理员操作(转派、跳转、终止等)的记录
 }
@@ -8,5 +8,8 @@ package model
 // 流程分类
 type Classify struct {
 	AuditableBase
-	Name string `json:"name" form:"name"` // 分类名称
+	Name     string `json:"name" form:"name"`                          // 分类名称
+	ParentId int    `gorm:"default:0" json:"parentId" form:"parentId"` // 父分类id, 0为顶级分类
+	Sort     int    `gorm:"default:0" json:"sort" form:"sort"`         // 排序, 越小越靠前
+	TenantId int    `gorm:"index" json:"tenantId" form:"tenantId"`     // 租户id
 }
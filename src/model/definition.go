@@ -6,6 +6,7 @@
 package model
 
 import (
+	"github.com/lib/pq"
 	"gorm.io/datatypes"
 
 	"workflow/src/model/dto"
@@ -14,13 +15,19 @@ import (
 // 流程定义表
 type ProcessDefinition struct {
 	AuditableBase
-	Name        string         `gorm:"column:name; type:varchar(128)" json:"name" form:"name"`                             // 流程名称
-	FormId      int            `json:"formId" form:"formId"`                                                               // 对应的表单的id(表单不存在于当前系统中，仅对外部系统做一个标记)
-	Structure   dto.Structure  `gorm:"column:structure; type:jsonb" json:"structure" form:"structure"`                     // 流程的具体结构
-	ClassifyId  int            `gorm:"column:classify_id; type:integer" json:"classifyId" form:"classifyId"`               // 分类ID
-	Task        datatypes.JSON `gorm:"column:task; type:jsonb" jsonb:"task" form:"task"`                                   // 任务ID, array, 可执行多个任务，可以当成通知任务，每个节点都会去执行
-	SubmitCount int            `gorm:"column:submit_count; type:integer; default:0" json:"submitCount" form:"submitCount"` // 提交统计
-	Notice      datatypes.JSON `gorm:"column:notice; type:jsonb" json:"notice" form:"notice"`                              // 绑定通知
-	TenantId    int            `gorm:"index" json:"tenantId" form:"tenantId"`                                              // 租户id
-	Remarks     string         `gorm:"column:remarks; type:text" json:"remarks" form:"remarks"`                            // 流程备注
+	Name           string         `gorm:"column:name; type:varchar(128)" json:"name" form:"name"`                             // 流程名称
+	FormId         int            `json:"formId" form:"formId"`                                                               // 对应的表单的id(表单不存在于当前系统中，仅对外部系统做一个标记)
+	Structure      dto.Structure  `gorm:"column:structure; type:jsonb" json:"structure" form:"structure"`                     // 流程的具体结构
+	ClassifyId     int            `gorm:"column:classify_id; type:integer" json:"classifyId" form:"classifyId"`               // 分类ID
+	Task           datatypes.JSON `gorm:"column:task; type:jsonb" jsonb:"task" form:"task"`                                   // 任务ID, array, 可执行多个任务，可以当成通知任务，每个节点都会去执行
+	SubmitCount    int            `gorm:"column:submit_count; type:integer; default:0" json:"submitCount" form:"submitCount"` // 提交统计
+	Notice         datatypes.JSON `gorm:"column:notice; type:jsonb" json:"notice" form:"notice"`                              // 绑定通知
+	TenantId       int            `gorm:"index" json:"tenantId" form:"tenantId"`                                              // 租户id
+	Remarks        string         `gorm:"column:remarks; type:text" json:"remarks" form:"remarks"`                            // 流程备注
+	Version        int            `gorm:"column:version; type:integer; default:1" json:"version" form:"version"`              // 版本号, 每次修改加1
+	StarterUsers   pq.StringArray `gorm:"type:text[]; default:array[]::text[]" json:"starterUsers" form:"starterUsers"`       // 可以发起流程的用户, 和StarterRoles都为空时所有人都可以发起
+	StarterRoles   pq.StringArray `gorm:"type:text[]; default:array[]::text[]" json:"starterRoles" form:"starterRoles"`       // 可以发起流程的角色
+	ViewerUsers    pq.StringArray `gorm:"type:text[]; default:array[]::text[]" json:"viewerUsers" form:"viewerUsers"`         // 可以查看该流程所有实例的用户(观察者)
+	ViewerRoles    pq.StringArray `gorm:"type:text[]; default:array[]::text[]" json:"viewerRoles" form:"viewerRoles"`         // 可以查看该流程所有实例的角色(观察者)
+	ViewerManagers bool           `gorm:"default:false" json:"viewerManagers" form:"viewerManagers"`                          // 发起人的各级上级是否可以查看发起人的流程实例
 }
@@ -0,0 +1,24 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/12 10:20
+ * @Desc: 流程定义的历史版本
+ */
+package model
+
+import (
+	"time"
+
+	"workflow/src/model/dto"
+)
+
+// 流程定义的历史版本, 每次新建或者修改流程定义都会保存一份快照
+type ProcessDefinitionVersion struct {
+	EntityBase
+	ProcessDefinitionId int           `gorm:"index;uniqueIndex:idx_definition_version" json:"processDefinitionId" form:"processDefinitionId"` // 流程定义id
+	Version             int           `gorm:"uniqueIndex:idx_definition_version" json:"version" form:"version"`                               // 版本号
+	Name                string        `gorm:"type:varchar(128)" json:"name" form:"name"`                                                      // 流程名称
+	Structure           dto.Structure `gorm:"type:jsonb" json:"structure" form:"structure"`                                                   // 流程的具体结构
+	TenantId            int           `gorm:"index" json:"tenantId" form:"tenantId"`                                                          // 租户id
+	CreateBy            string        `json:"createBy" form:"createBy"`                                                                       // 创建人
+	CreateTime          time.Time     `gorm:"default:now();type:timestamp" json:"createTime" form:"createTime"`                               // 创建时间
+}
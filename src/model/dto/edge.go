@@ -17,4 +17,5 @@ type Edge struct {
 	TargetAnchor        int64  `json:"targetAnchor"`
 	FlowProperties      string `json:"flowProperties"`
 	ConditionExpression string `json:"conditionExpression,omitempty"` // 表达式
+	IsDefault           bool   `json:"isDefault,omitempty"`           // 是否是排他网关的默认流向, 其他条件都不满足的时候走默认流向
 }
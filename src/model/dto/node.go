@@ -18,5 +18,8 @@ type Node struct {
 	AssignType    string   `json:"assignType,omitempty"`
 	ActiveOrder   bool     `json:"activeOrder,omitempty"`
 	AssignValue   []string `json:"assignValue,omitempty"`
+	AssignBackup  []string `json:"assignBackup,omitempty"` // 根据变量或者组织架构计算出的处理人为空的时候使用的处理人
 	IsCounterSign bool     `json:"isCounterSign,omitempty"`
+	CcUsers       []string `json:"ccUsers,omitempty"` // 进入节点时抄送的用户
+	CcRoles       []string `json:"ccRoles,omitempty"` // 进入节点时抄送的角色
 }
@@ -0,0 +1,22 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/20 10:10
+ * @Desc: 幂等请求的记录, 相同租户+用户+Idempotency-Key的重试请求直接返回第一次的响应
+ */
+package model
+
+import "time"
+
+type IdempotencyRecord struct {
+	EntityBase
+	TenantId       int       `gorm:"uniqueIndex:idx_idempotency_key" json:"tenantId"`       // 租户id
+	UserIdentifier string    `gorm:"uniqueIndex:idx_idempotency_key" json:"userIdentifier"` // 用户标识
+	Key            string    `gorm:"uniqueIndex:idx_idempotency_key" json:"key"`            // 请求头Idempotency-Key的值
+	RequestHash    string    `json:"requestHash"`                                           // 请求方法+路径+请求体的sha256, 相同的key不能用于不同的请求
+	IsCompleted    bool      `gorm:"default:false" json:"isCompleted"`                      // 第一次请求是否已经处理完成
+	StatusCode     int       `json:"statusCode"`                                            // 第一次请求的响应状态码
+	ContentType    string    `json:"contentType"`                                           // 第一次请求的响应类型
+	ResponseBody   []byte    `json:"-"`                                                     // 第一次请求的响应内容
+	CreateTime     time.Time `gorm:"default:now();type:timestamp" json:"createTime"`
+	ExpireTime     time.Time `gorm:"index;type:timestamp" json:"expireTime"` // 过期时间, 过期之后相同的key会当作新的请求
+}
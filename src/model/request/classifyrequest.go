@@ -0,0 +1,41 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/15 9:30
+ * @Desc: 流程分类
+ */
+package request
+
+import (
+	"time"
+
+	"workflow/src/model"
+)
+
+type ClassifyRequest struct {
+	Id       int    `json:"id" form:"id"`
+	Name     string `json:"name" form:"name"`         // 分类名称
+	ParentId int    `json:"parentId" form:"parentId"` // 父分类id, 0为顶级分类
+	Sort     int    `json:"sort" form:"sort"`         // 排序, 越小越靠前
+}
+
+func (r *ClassifyRequest) ToClassify(userIdentifier string, tenantId int) model.Classify {
+	return model.Classify{
+		AuditableBase: model.AuditableBase{
+			EntityBase: model.EntityBase{
+				Id: r.Id,
+			},
+			CreateTime: time.Now().Local(),
+			UpdateTime: time.Now().Local(),
+			CreateBy:   userIdentifier,
+			UpdateBy:   userIdentifier,
+		},
+		Name:     r.Name,
+		ParentId: r.ParentId,
+		Sort:     r.Sort,
+		TenantId: tenantId,
+	}
+}
+
+type GroupedDefinitionRequest struct {
+	Keyword string `json:"keyword,omitempty" form:"keyword,omitempty" query:"keyword"` // 流程名称关键词
+}
@@ -16,14 +16,19 @@ import (
 )
 
 type ProcessDefinitionRequest struct {
-	Id         int             `json:"id" form:"id"`
-	Name       string          `json:"name" form:"name"`                                // 流程名称
-	FormId     int             `json:"formId" form:"formId"`                            // 对应的表单的id(仅对外部系统做一个标记)
-	Structure  json.RawMessage `json:"structure" form:"structure" swaggertype:"string"` // 流程结构
-	ClassifyId int             `json:"classifyId" form:"classifyId"`                    // 分类ID
-	Task       json.RawMessage `json:"task" form:"task" swaggertype:"string"`           // 任务ID, array, 可执行多个任务，可以当成通知任务，每个节点都会去执行
-	Notice     json.RawMessage `json:"notice" form:"notice" swaggertype:"string"`       // 绑定通知
-	Remarks    string          `json:"remarks" form:"remarks"`                          // 流程备注
+	Id             int             `json:"id" form:"id"`
+	Name           string          `json:"name" form:"name"`                                // 流程名称
+	FormId         int             `json:"formId" form:"formId"`                            // 对应的表单的id(仅对外部系统做一个标记)
+	Structure      json.RawMessage `json:"structure" form:"structure" swaggertype:"string"` // 流程结构
+	ClassifyId     int             `json:"classifyId" form:"classifyId"`                    // 分类ID
+	Task           json.RawMessage `json:"task" form:"task" swaggertype:"string"`           // 任务ID, array, 可执行多个任务，可以当成通知任务，每个节点都会去执行
+	Notice         json.RawMessage `json:"notice" form:"notice" swaggertype:"string"`       // 绑定通知
+	Remarks        string          `json:"remarks" form:"remarks"`                          // 流程备注
+	StarterUsers   []string        `json:"starterUsers" form:"starterUsers"`                // 可以发起流程的用户, 和StarterRoles都为空时所有人都可以发起
+	StarterRoles   []string        `json:"starterRoles" form:"starterRoles"`                // 可以发起流程的角色
+	ViewerUsers    []string        `json:"viewerUsers" form:"viewerUsers"`                  // 可以查看该流程所有实例的用户(观察者)
+	ViewerRoles    []string        `json:"viewerRoles" form:"viewerRoles"`                  // 可以查看该流程所有实例的角色(观察者)
+	ViewerManagers bool            `json:"viewerManagers" form:"viewerManagers"`            // 发起人的各级上级是否可以查看发起人的流程实例
 }
 
 func (p *ProcessDefinitionRequest) ProcessDefinition() model.ProcessDefinition {
@@ -38,17 +43,47 @@ func (p *ProcessDefinitionRequest) ProcessDefinition() model.ProcessDefinition {
 			CreateTime: time.Now().Local(),
 			UpdateTime: time.Now().Local(),
 		},
-		Name:        p.Name,
-		Structure:   structure,
-		ClassifyId:  p.ClassifyId,
-		Task:        datatypes.JSON(p.Task),
-		Notice:      datatypes.JSON(p.Notice),
-		Remarks:     p.Remarks,
-		FormId:      p.FormId,
-		SubmitCount: 0,
+		Name:           p.Name,
+		Structure:      structure,
+		ClassifyId:     p.ClassifyId,
+		Task:           datatypes.JSON(p.Task),
+		Notice:         datatypes.JSON(p.Notice),
+		Remarks:        p.Remarks,
+		FormId:         p.FormId,
+		SubmitCount:    0,
+		StarterUsers:   emptyIfNil(p.StarterUsers),
+		StarterRoles:   emptyIfNil(p.StarterRoles),
+		ViewerUsers:    emptyIfNil(p.ViewerUsers),
+		ViewerRoles:    emptyIfNil(p.ViewerRoles),
+		ViewerManagers: p.ViewerManagers,
 	}
 }
 
+type StartableDefinitionRequest struct {
+	Keyword string `json:"keyword,omitempty" form:"keyword,omitempty" query:"keyword"` // 流程名称关键词
+}
+
 type CloneDefinitionRequest struct {
 	Id int `json:"id" form:"id"`
 }
+
+// 对比两个流程定义(或者同一个流程定义的两个版本)
+type DefinitionDiffRequest struct {
+	SourceId      int `json:"sourceId" form:"sourceId" query:"sourceId"`                // 源流程定义id
+	SourceVersion int `json:"sourceVersion" form:"sourceVersion" query:"sourceVersion"` // 源流程定义的版本, 不传则为当前版本
+	TargetId      int `json:"targetId" form:"targetId" query:"targetId"`                // 目标流程定义id, 不传则和源流程定义相同
+	TargetVersion int `json:"targetVersion" form:"targetVersion" query:"targetVersion"` // 目标流程定义的版本, 不传则为当前版本
+}
+
+type DefinitionVersionListRequest struct {
+	PagingRequest
+	Id int `json:"id" path:"id" swaggerignore:"true"` // 流程定义id
+}
+
+func emptyIfNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+
+	return s
+}
@@ -9,7 +9,7 @@ import "workflow/src/model"
 
 // 审批/处理流程实例的接口的请求体
 type HandleInstancesRequest struct {
-	EdgeId            string                   `json:"edgeId" form:"edgeId"`                       // 走的流程的id
+	EdgeId            string                   `json:"edgeId" form:"edgeId"`                       // 走的流程的id, 为空则在当前节点同意的顺序流中根据条件表达式选择
 	ProcessInstanceId int                      `json:"processInstanceId" form:"processInstanceId"` // 流程实例的id
 	Remarks           string                   `json:"remarks" form:"remarks"`                     // 备注
 	Variables         []model.InstanceVariable `json:"variables"`                                  // 变量
@@ -21,3 +21,45 @@ type DenyInstanceRequest struct {
 	NodeId            string `json:"nodeId" form:"nodeId"`                       // 所在节点的id
 	Remarks           string `json:"remarks" form:"remarks"`                     // 备注
 }
+
+// 批量审批的请求体
+type BatchHandleInstancesRequest struct {
+	Items   []BatchHandleItem `json:"items"`   // 需要处理的流程实例
+	Remarks string            `json:"remarks"` // 备注, item上没有备注的时候使用
+}
+
+// 批量审批中的一项
+type BatchHandleItem struct {
+	ProcessInstanceId int    `json:"processInstanceId"` // 流程实例的id
+	EdgeId            string `json:"edgeId"`            // 走的流程的id, 为空则走当前节点默认同意的顺序流
+	IsDeny            bool   `json:"isDeny"`            // 是否否决, 为true的时候忽略edgeId
+	Remarks           string `json:"remarks"`           // 备注
+}
+
+// 管理员强制跳转到指定节点的请求体
+type AdminJumpRequest struct {
+	ProcessInstanceId int    `json:"processInstanceId" form:"processInstanceId"` // 流程实例的id
+	NodeId            string `json:"nodeId" form:"nodeId"`                       // 跳转的目标节点id, 只能是用户任务或者结束节点
+	Remarks           string `json:"remarks" form:"remarks"`                     // 备注
+}
+
+// 管理员修改当前节点处理人的请求体
+type AdminReassignRequest struct {
+	ProcessInstanceId int      `json:"processInstanceId" form:"processInstanceId"` // 流程实例的id
+	NodeId            string   `json:"nodeId" form:"nodeId"`                       // 当前所在节点的id
+	FromUser          string   `json:"fromUser" form:"fromUser"`                   // 被替换的处理人, 为空则替换所有未处理的人
+	ToUsers           []string `json:"toUsers" form:"toUsers"`                     // 新的处理人
+	Remarks           string   `json:"remarks" form:"remarks"`                     // 备注
+}
+
+// 管理员终止流程的请求体
+type AdminTerminateRequest struct {
+	ProcessInstanceId int    `json:"processInstanceId" form:"processInstanceId"` // 流程实例的id
+	Remarks           string `json:"remarks" form:"remarks"`                     // 备注
+}
+
+// 管理员删除/恢复流程的请求体
+type AdminDeleteRequest struct {
+	Id      int    `json:"id" form:"id" param:"id" swaggerignore:"true"`
+	Remarks string `json:"remarks" form:"remarks" query:"remarks"` // 备注
+}
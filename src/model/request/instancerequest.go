@@ -33,6 +33,7 @@ func (i *ProcessInstanceRequest) ToProcessInstance(currentUserId string, tenantI
 		ProcessDefinitionId: i.ProcessDefinitionId,
 		TenantId:            tenantId,
 		Variables:           util.MarshalToDbJson(i.Variables),
+		Version:             1,
 	}
 }
 
@@ -42,11 +43,12 @@ type GetInstanceRequest struct {
 }
 
 type GetVariableRequest struct {
-	InstanceId   int    `json:"instanceId,omitempty" form:"instanceId,omitempty"`
-	VariableName string `json:"variableName,omitempty" form:"variableName,omitempty"`
+	InstanceId   int    `json:"instanceId,omitempty" form:"instanceId,omitempty" param:"id" swaggerignore:"true"`
+	VariableName string `json:"variableName,omitempty" form:"variableName,omitempty" param:"name" swaggerignore:"true"`
 }
 
 type GetVariableListRequest struct {
 	PagingRequest
-	InstanceId int `json:"instanceId,omitempty" form:"instanceId,omitempty"`
+	InstanceId int    `json:"instanceId,omitempty" form:"instanceId,omitempty" param:"id" swaggerignore:"true"`
+	Name       string `json:"name,omitempty" form:"name,omitempty" query:"name"` // 变量名, 查询变更历史的时候为空则查询所有变量
 }
@@ -6,7 +6,7 @@
 package request
 
 type PagingRequest struct {
-	Sort   string `json:"sort,omitempty" form:"sort,omitempty" query:"sort"`       // 排序键的名字，在各查询实现中默认值与可用值都不同
+	Sort   string `json:"sort,omitempty" form:"sort,omitempty" query:"sort"`       // 排序键的名字，在各查询实现中默认值与可用值都不同, 不可用的会返回400
 	Order  string `json:"order,omitempty" form:"order,omitempty" query:"order"`    // asc或者是desc
 	Offset int    `json:"offset,omitempty" form:"offset,omitempty" query:"offset"` // 跳过的条数
 	Limit  int    `json:"limit,omitempty" form:"limit,omitempty" query:"limit"`    // 取的条数
@@ -14,8 +14,18 @@ type PagingRequest struct {
 
 type InstanceListRequest struct {
 	PagingRequest
-	Type    int    `json:"type,omitempty" form:"type" query:"type"`                    // 类别 1=我的待办 2=我创建的 3=和我相关的 4=所有
-	Keyword string `json:"keyword,omitempty" form:"keyword,omitempty" query:"keyword"` // 关键词
+	Type                int    `json:"type,omitempty" form:"type" query:"type"`                                                        // 类别 1=我的待办 2=我创建的 3=和我相关的 4=所有(有权限查看的) 5=我处理过的
+	Keyword             string `json:"keyword,omitempty" form:"keyword,omitempty" query:"keyword"`                                     // 关键词
+	ProcessDefinitionId int    `json:"processDefinitionId,omitempty" form:"processDefinitionId,omitempty" query:"processDefinitionId"` // 流程定义id
+	ClassifyId          int    `json:"classifyId,omitempty" form:"classifyId,omitempty" query:"classifyId"`                            // 分类id
+	Priority            int    `json:"priority,omitempty" form:"priority,omitempty" query:"priority"`                                  // 优先级
+	Status              int    `json:"status,omitempty" form:"status,omitempty" query:"status"`                                        // 状态 1=进行中 2=已结束 3=已否决
+	Initiator           string `json:"initiator,omitempty" form:"initiator,omitempty" query:"initiator"`                               // 发起人
+	CurrentNodeId       string `json:"currentNodeId,omitempty" form:"currentNodeId,omitempty" query:"currentNodeId"`                   // 当前所在节点id
+	CreateTimeStart     string `json:"createTimeStart,omitempty" form:"createTimeStart,omitempty" query:"createTimeStart"`             // 创建时间起 yyyy-MM-dd HH:mm:ss
+	CreateTimeEnd       string `json:"createTimeEnd,omitempty" form:"createTimeEnd,omitempty" query:"createTimeEnd"`                   // 创建时间止 yyyy-MM-dd HH:mm:ss
+	UpdateTimeStart     string `json:"updateTimeStart,omitempty" form:"updateTimeStart,omitempty" query:"updateTimeStart"`             // 更新时间起 yyyy-MM-dd HH:mm:ss
+	UpdateTimeEnd       string `json:"updateTimeEnd,omitempty" form:"updateTimeEnd,omitempty" query:"updateTimeEnd"`                   // 更新时间止 yyyy-MM-dd HH:mm:ss
 }
 
 type DefinitionListRequest struct {
@@ -13,14 +13,24 @@ import (
 
 // 同步
 type BatchSyncUserRoleRequest struct {
-	Users     []UserRequest     `json:"users"`
-	Roles     []RoleRequest     `json:"roles"`
-	UserRoles []UserRoleRequest `json:"userRoles"`
+	Users       []UserRequest       `json:"users"`
+	Roles       []RoleRequest       `json:"roles"`
+	UserRoles   []UserRoleRequest   `json:"userRoles"`
+	Departments []DepartmentRequest `json:"departments"`
 }
 
 type UserRequest struct {
-	Identifier string `json:"identifier"`
-	Name       string `json:"name"`
+	Identifier           string `json:"identifier"`
+	Name                 string `json:"name"`
+	DepartmentIdentifier string `json:"departmentIdentifier"` // 所属部门的id
+	ManagerIdentifier    string `json:"managerIdentifier"`    // 直属上级的用户id
+}
+
+type DepartmentRequest struct {
+	Identifier       string `json:"identifier"`
+	Name             string `json:"name"`
+	ParentIdentifier string `json:"parentIdentifier"` // 上级部门的id
+	LeaderIdentifier string `json:"leaderIdentifier"` // 部门负责人的用户id
 }
 
 type RoleRequest struct {
@@ -33,13 +43,15 @@ type UserRoleRequest struct {
 	RoleIdentifier string `json:"roleIdentifier"`
 }
 
-func (s *BatchSyncUserRoleRequest) ToDbEntities(tenantId int) (users []model.User, roles []model.Role, userRole []model.UserRole) {
+func (s *BatchSyncUserRoleRequest) ToDbEntities(tenantId int) (users []model.User, roles []model.Role, userRole []model.UserRole, departments []model.Department) {
 	if s.Users != nil {
 		linq.From(s.Users).SelectT(func(i UserRequest) interface{} {
 			return model.User{
-				Identifier: i.Identifier,
-				Name:       i.Name,
-				TenantId:   tenantId,
+				Identifier:           i.Identifier,
+				Name:                 i.Name,
+				DepartmentIdentifier: i.DepartmentIdentifier,
+				ManagerIdentifier:    i.ManagerIdentifier,
+				TenantId:             tenantId,
 			}
 		}).ToSlice(&users)
 	}
@@ -63,5 +75,17 @@ func (s *BatchSyncUserRoleRequest) ToDbEntities(tenantId int) (users []model.Use
 		}).ToSlice(&userRole)
 	}
 
+	if s.Departments != nil {
+		linq.From(s.Departments).SelectT(func(i DepartmentRequest) interface{} {
+			return model.Department{
+				Identifier:       i.Identifier,
+				Name:             i.Name,
+				ParentIdentifier: i.ParentIdentifier,
+				LeaderIdentifier: i.LeaderIdentifier,
+				TenantId:         tenantId,
+			}
+		}).ToSlice(&departments)
+	}
+
 	return
 }
@@ -5,6 +5,18 @@
  */
 package request
 
-type TaskRequest struct {
+type TaskListRequest struct {
+	PagingRequest
+	Status  int    `json:"status,omitempty" form:"status" query:"status"`              // 状态 1=待处理 2=已处理 3=已取消, 不传则为全部
+	Keyword string `json:"keyword,omitempty" form:"keyword,omitempty" query:"keyword"` // 关键词(流程实例标题)
+}
+
+type CcTaskListRequest struct {
+	PagingRequest
+	Unread  bool   `json:"unread,omitempty" form:"unread" query:"unread"`              // 是否只查询未读的抄送
+	Keyword string `json:"keyword,omitempty" form:"keyword,omitempty" query:"keyword"` // 关键词(流程实例标题)
+}
 
+type ReadCcTaskRequest struct {
+	Id int `json:"id" form:"id" param:"id"` // 抄送任务的id
 }
@@ -0,0 +1,58 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/16 14:50
+ * @Desc: 租户管理
+ */
+package request
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/datatypes"
+
+	"workflow/src/model"
+)
+
+type TenantRequest struct {
+	Id          int             `json:"id" form:"id"`
+	Name        string          `json:"name" form:"name"`                              // 租户编码, 对应请求头中的WF-TENANT-CODE
+	DisplayName string          `json:"displayName" form:"displayName"`                // 租户显示名称
+	TimeZone    string          `json:"timeZone" form:"timeZone"`                      // 时区, 比如Asia/Shanghai
+	Locale      string          `json:"locale" form:"locale"`                          // 语言区域, 比如zh-CN
+	Settings    json.RawMessage `json:"settings" form:"settings" swaggertype:"object"` // 租户的自定义设置
+}
+
+func (r *TenantRequest) ToTenant() model.Tenant {
+	settings := r.Settings
+	if len(settings) == 0 {
+		settings = json.RawMessage("{}")
+	}
+
+	return model.Tenant{
+		EntityBase: model.EntityBase{
+			Id: r.Id,
+		},
+		Name:        r.Name,
+		DisplayName: r.DisplayName,
+		TimeZone:    r.TimeZone,
+		Locale:      r.Locale,
+		Settings:    datatypes.JSON(settings),
+		CreateTime:  time.Now().Local(),
+		UpdateTime:  time.Now().Local(),
+	}
+}
+
+type TenantListRequest struct {
+	PagingRequest
+	Keyword    string `json:"keyword,omitempty" form:"keyword,omitempty" query:"keyword"`          // 租户编码或者显示名称的关键词
+	IsDisabled *bool  `json:"isDisabled,omitempty" form:"isDisabled,omitempty" query:"isDisabled"` // 是否已禁用, 不传则返回全部
+}
+
+type ApiKeyRequest struct {
+	TenantId           int        `json:"-" param:"id" swaggerignore:"true"`            // 租户id, 取自路径参数
+	Name               string     `json:"name" form:"name"`                             // 名称, 比如调用方的系统名称
+	UserIdentifier     string     `json:"userIdentifier" form:"userIdentifier"`         // 使用该key时的用户标识
+	AllowImpersonation bool       `json:"allowImpersonation" form:"allowImpersonation"` // 是否允许通过WF-CURRENT-USER请求头代替其他用户操作
+	ExpireTime         *time.Time `json:"expireTime" form:"expireTime"`                 // 过期时间, 为空则不过期
+}
@@ -0,0 +1,51 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/20 15:10
+ * @Desc: 当前用户对流程实例可以进行的操作
+ */
+package response
+
+// 顺序流的操作语义, 根据flowProperties判断
+const (
+	EdgeActionApprove = "approve" // 同意, flowProperties为"1"
+	EdgeActionReject  = "reject"  // 拒绝, flowProperties为"0"
+	EdgeActionOther   = "other"   // 其他
+)
+
+type InstanceActionsResponse struct {
+	ProcessInstanceId int           `json:"processInstanceId"` // 流程实例的id
+	IsEnd             bool          `json:"isEnd"`             // 是否已结束
+	IsDenied          bool          `json:"isDenied"`          // 是否已被否决
+	Nodes             []NodeActions `json:"nodes"`             // 当前用户待处理的节点
+	CanTransfer       bool          `json:"canTransfer"`       // 是否可以转派(管理员, /_reassign)
+	CanJump           bool          `json:"canJump"`           // 是否可以强制跳转(管理员, /_jump)
+	CanTerminate      bool          `json:"canTerminate"`      // 是否可以终止(管理员, /_terminate)
+	CanAddSign        bool          `json:"canAddSign"`        // 是否可以加签, 暂不支持
+	CanWithdraw       bool          `json:"canWithdraw"`       // 是否可以撤回, 暂不支持
+	CanUrge           bool          `json:"canUrge"`           // 是否可以催办, 暂不支持
+}
+
+// 当前用户在某个节点上可以进行的操作
+type NodeActions struct {
+	NodeId          string       `json:"nodeId"`          // 节点id, 否决的时候使用
+	NodeLabel       string       `json:"nodeLabel"`       // 节点名称
+	IsCounterSign   bool         `json:"isCounterSign"`   // 是否是会签
+	CanDeny         bool         `json:"canDeny"`         // 是否可以否决(/_deny)
+	ConditionRouted bool         `json:"conditionRouted"` // 同意的顺序流是否根据条件选择, 为true的时候同意不传edgeId, 根据提交的变量选择流向
+	Edges           []EdgeAction `json:"edges"`           // 可以走的顺序流(/_handle)
+}
+
+type EdgeAction struct {
+	EdgeId            string             `json:"edgeId"`            // 顺序流id, 审批的时候使用
+	Label             string             `json:"label"`             // 顺序流名称, 一般作为按钮的名称
+	Action            string             `json:"action"`            // approve: 同意 reject: 拒绝 other: 其他
+	IsDefaultApprove  bool               `json:"isDefaultApprove"`  // 是否是唯一的同意的顺序流(不传edgeId的时候走这条), 根据条件选择的时候为false
+	TargetNodeId      string             `json:"targetNodeId"`      // 目标节点id
+	TargetNodeLabel   string             `json:"targetNodeLabel"`   // 目标节点名称
+	RequiredVariables []RequiredVariable `json:"requiredVariables"` // 后续网关的条件表达式用到的变量
+}
+
+type RequiredVariable struct {
+	Name       string `json:"name"`       // 变量名
+	IsProvided bool   `json:"isProvided"` // 流程实例上是否已经有该变量
+}
@@ -0,0 +1,14 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/18 14:10
+ * @Desc: 租户的api key
+ */
+package response
+
+import "workflow/src/model"
+
+// 创建api key的返回, key的明文只在创建时返回一次
+type ApiKeyResponse struct {
+	model.ApiKey
+	Key string `json:"key"` // api key的明文
+}
@@ -0,0 +1,21 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/15 9:42
+ * @Desc: 流程分类
+ */
+package response
+
+import "workflow/src/model"
+
+// 分类树
+type ClassifyTreeNode struct {
+	model.Classify
+	Children []ClassifyTreeNode `json:"children"` // 子分类
+}
+
+// 按分类分组的流程定义(用于发起流程的页面)
+type ClassifyDefinitionGroup struct {
+	model.Classify
+	Definitions []model.ProcessDefinition `json:"definitions"` // 当前分类下的流程定义
+	Children    []ClassifyDefinitionGroup `json:"children"`    // 子分类
+}
@@ -0,0 +1,47 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/12 11:02
+ * @Desc: 流程定义对比结果
+ */
+package response
+
+import "workflow/src/model/dto"
+
+type DefinitionDiffResponse struct {
+	Source  DiffDefinition `json:"source"`  // 源流程定义
+	Target  DiffDefinition `json:"target"`  // 目标流程定义
+	Nodes   NodeDiff       `json:"nodes"`   // 节点的变化
+	Edges   EdgeDiff       `json:"edges"`   // 顺序流的变化
+	Summary []string       `json:"summary"` // 可读的变化说明
+}
+
+type DiffDefinition struct {
+	Id      int    `json:"id"`
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+}
+
+type NodeDiff struct {
+	Added   []dto.Node   `json:"added"`
+	Removed []dto.Node   `json:"removed"`
+	Changed []ItemChange `json:"changed"`
+}
+
+type EdgeDiff struct {
+	Added   []dto.Edge   `json:"added"`
+	Removed []dto.Edge   `json:"removed"`
+	Changed []ItemChange `json:"changed"`
+}
+
+// 某个节点或者顺序流的变化
+type ItemChange struct {
+	Id      string        `json:"id"`
+	Label   string        `json:"label"`
+	Changes []FieldChange `json:"changes"`
+}
+
+type FieldChange struct {
+	Field    string      `json:"field"`    // 变化的字段
+	OldValue interface{} `json:"oldValue"` // 原值
+	NewValue interface{} `json:"newValue"` // 新值
+}
@@ -6,6 +6,8 @@
 package response
 
 import (
+	"time"
+
 	"workflow/src/global/constant"
 	"workflow/src/model"
 )
@@ -21,6 +23,15 @@ type ProcessInstanceResponse struct {
 	ProcessChainNodes []ProcessChainNode `json:"processChainNodes,omitempty"` // 流程链路【包括全部节点和当前节点】
 }
 
+// 各个列表类型的数量
+type InstanceCountResponse struct {
+	MyToDo   int64 `json:"myToDo"`   // 我的待办
+	ICreated int64 `json:"iCreated"` // 我创建的
+	IRelated int64 `json:"iRelated"` // 和我相关的
+	IHandled int64 `json:"iHandled"` // 我处理过的
+	All      int64 `json:"all"`      // 所有
+}
+
 type ProcessChainNode struct {
 	Name       string                   `json:"name"`
 	Id         string                   `json:"id"`
@@ -29,3 +40,26 @@ type ProcessChainNode struct {
 	NodeType   int                      `json:"nodeType"`   // 1. 开始事件 2. 用户任务 3. 排他网关 4. 结束事件
 	Obligatory bool                     `json:"obligatory"` // 是否必经节点
 }
+
+// 批量审批的结果
+type BatchHandleResponse struct {
+	SuccessCount int                     `json:"successCount"` // 成功的数量
+	FailedCount  int                     `json:"failedCount"`  // 失败的数量
+	Items        []BatchHandleItemResult `json:"items"`        // 每一项的处理结果, 和请求的顺序一致
+}
+
+type BatchHandleItemResult struct {
+	ProcessInstanceId int    `json:"processInstanceId"` // 流程实例的id
+	Success           bool   `json:"success"`           // 是否处理成功
+	Message           string `json:"message,omitempty"` // 失败的原因
+	IsEnd             bool   `json:"isEnd"`             // 处理之后流程是否已经结束
+	IsDenied          bool   `json:"isDenied"`          // 处理之后流程是否已经被否决
+}
+
+// 流程实例时间线中的一项, 流转记录和变量变更按照时间先后排列
+type TimelineItem struct {
+	Type           string                    `json:"type"`                     // circulation: 流转记录 variable: 变量变更
+	Time           time.Time                 `json:"time"`                     // 发生时间
+	Circulation    *model.CirculationHistory `json:"circulation,omitempty"`    // 流转记录
+	VariableChange *model.VariableHistory    `json:"variableChange,omitempty"` // 变量变更
+}
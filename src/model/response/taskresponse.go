@@ -5,7 +5,11 @@
  */
 package response
 
-type TaskResponse struct {
-
+import "workflow/src/model"
 
+type TaskResponse struct {
+	model.Task
+	Title     string `json:"title"`     // 流程实例标题
+	Priority  int    `json:"priority"`  // 流程实例优先级
+	Initiator string `json:"initiator"` // 流程实例发起人
 }
@@ -0,0 +1,26 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/13 9:40
+ * @Desc: 待办任务
+ */
+package model
+
+import "time"
+
+// 待办任务, 一个处理人在一个节点上对应一条, 由引擎在每次流转时维护
+type Task struct {
+	EntityBase
+	ProcessInstanceId   int        `gorm:"index" json:"processInstanceId" form:"processInstanceId"`                              // 流程实例id
+	ProcessDefinitionId int        `gorm:"type:integer" json:"processDefinitionId" form:"processDefinitionId"`                   // 流程定义id
+	NodeId              string     `json:"nodeId" form:"nodeId"`                                                                 // 节点id
+	NodeLabel           string     `json:"nodeLabel" form:"nodeLabel"`                                                           // 节点名称
+	Assignee            string     `gorm:"index:idx_task_assignee_status,priority:2" json:"assignee" form:"assignee"`            // 处理人外部系统ID
+	Status              int        `gorm:"type:smallint; index:idx_task_assignee_status,priority:3" json:"status" form:"status"` // 状态 1=待处理 2=已处理 3=已取消 4=抄送未读 5=抄送已读
+	Kind                int        `gorm:"type:smallint; default:1; not null" json:"kind" form:"kind"`                           // 类型 1=审批 2=抄送
+	Outcome             string     `json:"outcome" form:"outcome"`                                                               // 处理结果(走的顺序流或者否决)
+	IsCounterSign       bool       `gorm:"default:false" json:"isCounterSign" form:"isCounterSign"`                              // 是否会签
+	TenantId            int        `gorm:"index:idx_task_assignee_status,priority:1" json:"tenantId" form:"tenantId"`            // 租户id
+	CreateTime          time.Time  `gorm:"default:now();type:timestamp" json:"createTime" form:"createTime"`                     // 创建时间
+	CompleteTime        *time.Time `gorm:"type:timestamp" json:"completeTime" form:"completeTime"`                               // 完成时间
+	DueTime             *time.Time `gorm:"type:timestamp" json:"dueTime" form:"dueTime"`                                         // 截止时间
+}
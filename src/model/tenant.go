@@ -5,10 +5,20 @@
  */
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
 
 type Tenant struct {
 	EntityBase
-	Name       string    `json:"name"`
-	CreateTime time.Time `gorm:"default:now();type:timestamp" json:"createTime" form:"createTime"`
+	Name        string         `gorm:"uniqueIndex" json:"name"`                         // 租户编码, 对应请求头中的WF-TENANT-CODE
+	DisplayName string         `json:"displayName"`                                     // 租户显示名称
+	TimeZone    string         `json:"timeZone"`                                        // 时区, 比如Asia/Shanghai
+	Locale      string         `json:"locale"`                                          // 语言区域, 比如zh-CN
+	Settings    datatypes.JSON `gorm:"type:jsonb" json:"settings" swaggertype:"object"` // 租户的自定义设置
+	IsDisabled  bool           `gorm:"default:false" json:"isDisabled"`                 // 是否已禁用, 禁用的租户所有请求都会被拒绝
+	CreateTime  time.Time      `gorm:"default:now();type:timestamp" json:"createTime" form:"createTime"`
+	UpdateTime  time.Time      `gorm:"default:now();type:timestamp" json:"updateTime" form:"updateTime"`
 }
@@ -12,10 +12,23 @@ import (
 // 外部系统的用户表
 type User struct {
 	EntityBase
-	Identifier string    `gorm:"index" json:"identifier"` // 外部系统用户id
-	Name       string    `json:"name"`                    // 用户名称
-	TenantId   int       `gorm:"index" json:"tenantId"`   // 租户id
-	CreateTime time.Time `gorm:"default:now();type:timestamp" json:"createTime"`
+	Identifier           string    `gorm:"index" json:"identifier"`           // 外部系统用户id
+	Name                 string    `json:"name"`                              // 用户名称
+	DepartmentIdentifier string    `gorm:"index" json:"departmentIdentifier"` // 所属部门的id
+	ManagerIdentifier    string    `gorm:"index" json:"managerIdentifier"`    // 直属上级的用户id
+	TenantId             int       `gorm:"index" json:"tenantId"`             // 租户id
+	CreateTime           time.Time `gorm:"default:now();type:timestamp" json:"createTime"`
+}
+
+// 外部系统的部门表
+type Department struct {
+	EntityBase
+	Identifier       string    `gorm:"index" json:"identifier"` // 外部系统部门id
+	Name             string    `json:"name"`                    // 部门名称
+	ParentIdentifier string    `json:"parentIdentifier"`        // 上级部门的id, 为空表示顶级部门
+	LeaderIdentifier string    `json:"leaderIdentifier"`        // 部门负责人的用户id
+	TenantId         int       `gorm:"index" json:"tenantId"`   // 租户id
+	CreateTime       time.Time `gorm:"default:now();type:timestamp" json:"createTime"`
 }
 
 // 外部系统的角色表
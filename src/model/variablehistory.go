@@ -0,0 +1,26 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/21 9:40
+ * @Desc: 流程变量的变更历史
+ */
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// 流程变量的变更历史, 发起和处理的时候每个发生变化的变量记录一条
+type VariableHistory struct {
+	EntityBase
+	ProcessInstanceId int            `gorm:"index" json:"processInstanceId"` // 流程实例id
+	Name              string         `json:"name"`                           // 变量名
+	OldValue          datatypes.JSON `gorm:"type:jsonb" json:"oldValue"`     // 变更前的值, 新增的变量为null
+	NewValue          datatypes.JSON `gorm:"type:jsonb" json:"newValue"`     // 变更后的值
+	NodeId            string         `json:"nodeId"`                         // 变更时所在的节点id
+	NodeLabel         string         `json:"nodeLabel"`                      // 变更时所在的节点名称
+	ChangedBy         string         `json:"changedBy"`                      // 变更人
+	TenantId          int            `gorm:"index" json:"tenantId"`          // 租户id
+	CreateTime        time.Time      `gorm:"default:now();type:timestamp" json:"createTime"`
+}
@@ -15,12 +15,29 @@ import (
 func RegisterProcessDefinition(r *echo.Group) {
 	processGroup := r.Group("/process-definitions")
 	{
-		processGroup.POST("", controller.CreateProcessDefinition)       // 新建
-		processGroup.PUT("", controller.UpdateProcessDefinition)        // 修改
-		processGroup.DELETE("/:id", controller.DeleteProcessDefinition) // 删除
-		processGroup.GET("/:id", controller.GetProcessDefinition)       // 获取流程
-		processGroup.GET("", controller.ListProcessDefinition)          // 获取列表
-		processGroup.POST("/_clone", controller.CloneProcessDefinition) // 克隆
+		processGroup.POST("", controller.CreateProcessDefinition)                   // 新建
+		processGroup.PUT("", controller.UpdateProcessDefinition)                    // 修改
+		processGroup.DELETE("/:id", controller.DeleteProcessDefinition)             // 删除
+		processGroup.GET("/:id", controller.GetProcessDefinition)                   // 获取流程
+		processGroup.GET("", controller.ListProcessDefinition)                      // 获取列表
+		processGroup.POST("/_clone", controller.CloneProcessDefinition)             // 克隆
+		processGroup.GET("/:id/export", controller.ExportProcessDefinition)         // 导出mermaid/dot
+		processGroup.GET("/_diff", controller.DiffProcessDefinition)                // 对比
+		processGroup.GET("/:id/versions", controller.ListProcessDefinitionVersions) // 历史版本
+		processGroup.GET("/_grouped", controller.ListGroupedProcessDefinitions)     // 按分类分组
+		processGroup.GET("/_startable", controller.ListStartableProcessDefinitions) // 当前用户可以发起的流程
+	}
+}
+
+// 流程分类
+func RegisterClassify(r *echo.Group) {
+	classifyGroup := r.Group("/classifies")
+	{
+		classifyGroup.POST("", controller.CreateClassify)       // 新建
+		classifyGroup.PUT("", controller.UpdateClassify)        // 修改
+		classifyGroup.DELETE("/:id", controller.DeleteClassify) // 删除
+		classifyGroup.GET("/:id", controller.GetClassify)       // 获取分类
+		classifyGroup.GET("", controller.ListClassifyTree)      // 获取分类树
 	}
 }
 
@@ -28,13 +45,37 @@ func RegisterProcessDefinition(r *echo.Group) {
 func RegisterProcessInstance(r *echo.Group) {
 	instanceGroup := r.Group("/process-instances")
 	{
-		instanceGroup.POST("", controller.CreateProcessInstance)          // 新建流程
-		instanceGroup.GET("/:id", controller.GetProcessInstance)          // 获取
-		instanceGroup.GET("", controller.ListProcessInstances)            // 获取列表
-		instanceGroup.POST("/_handle", controller.HandleProcessInstance)  // 流程审批
-		instanceGroup.POST("/_deny", controller.DenyProcessInstance)      // 流程否决
-		instanceGroup.GET("/:id/history", controller.ListHistory)         // 获取流程链路
-		instanceGroup.GET("/:id/train-nodes", controller.GetProcessTrain) // 获取流程链路
+		instanceGroup.POST("", controller.CreateProcessInstance)                      // 新建流程
+		instanceGroup.GET("/:id", controller.GetProcessInstance)                      // 获取
+		instanceGroup.GET("", controller.ListProcessInstances)                        // 获取列表
+		instanceGroup.POST("/_handle", controller.HandleProcessInstance)              // 流程审批
+		instanceGroup.POST("/_deny", controller.DenyProcessInstance)                  // 流程否决
+		instanceGroup.POST("/_batch-handle", controller.BatchHandleProcessInstances)  // 批量审批
+		instanceGroup.GET("/:id/variables", controller.ListInstanceVariables)         // 变量列表
+		instanceGroup.GET("/:id/variables/:name", controller.GetInstanceVariable)     // 单个变量
+		instanceGroup.GET("/:id/variable-history", controller.ListVariableHistory)    // 变量变更历史
+		instanceGroup.GET("/:id/timeline", controller.GetInstanceTimeline)            // 时间线
+		instanceGroup.GET("/:id/actions", controller.GetInstanceActions)              // 当前用户可以进行的操作
+		instanceGroup.GET("/:id/history", controller.ListHistory)                     // 获取流程链路
+		instanceGroup.GET("/:id/train-nodes", controller.GetProcessTrain)             // 获取流程链路
+		instanceGroup.GET("/:id/diagram.svg", controller.GetProcessInstanceDiagram)   // 获取svg流程图
+		instanceGroup.GET("/_counts", controller.CountProcessInstances)               // 各列表数量
+		instanceGroup.GET("/_stream", controller.StreamProcessInstanceEvents)         // 实时推送(SSE)
+		instanceGroup.POST("/_jump", controller.AdminJumpProcessInstance)             // 管理员强制跳转
+		instanceGroup.POST("/_reassign", controller.AdminReassignProcessInstance)     // 管理员转派
+		instanceGroup.POST("/_terminate", controller.AdminTerminateProcessInstance)   // 管理员终止
+		instanceGroup.DELETE("/:id", controller.AdminDeleteProcessInstance)           // 管理员删除
+		instanceGroup.POST("/:id/_undelete", controller.AdminUndeleteProcessInstance) // 管理员恢复
+	}
+}
+
+// 待办任务
+func RegisterTask(r *echo.Group) {
+	taskGroup := r.Group("/tasks")
+	{
+		taskGroup.GET("", controller.ListTasks)             // 获取当前用户的任务列表
+		taskGroup.GET("/cc", controller.ListCcTasks)        // 获取当前用户的抄送列表
+		taskGroup.POST("/:id/_read", controller.ReadCcTask) // 抄送标记为已读
 	}
 }
 
@@ -46,3 +87,19 @@ func RegisterRoleUsers(r *echo.Group) {
 		//instanceGroup.POST("", controller.SyncRoleUsers)             // 单条更新
 	}
 }
+
+// 租户管理
+func RegisterTenant(r *echo.Group) {
+	tenantGroup := r.Group("/tenants")
+	{
+		tenantGroup.POST("", controller.CreateTenant)                               // 新建
+		tenantGroup.PUT("", controller.UpdateTenant)                                // 修改
+		tenantGroup.GET("/:id", controller.GetTenant)                               // 获取租户
+		tenantGroup.GET("", controller.ListTenants)                                 // 获取列表
+		tenantGroup.POST("/:id/_disable", controller.DisableTenant)                 // 禁用
+		tenantGroup.POST("/:id/_enable", controller.EnableTenant)                   // 启用
+		tenantGroup.POST("/:id/api-keys", controller.CreateApiKey)                  // 创建api key
+		tenantGroup.GET("/:id/api-keys", controller.ListApiKeys)                    // api key列表
+		tenantGroup.POST("/:id/api-keys/:keyId/_disable", controller.DisableApiKey) // 禁用api key
+	}
+}
@@ -30,11 +30,19 @@ func Setup() *echo.Echo {
 		RegisterSwagger(r)
 	}
 
+	// 管理接口
+	admin := r.Group("/api/wf/admin", customMiddleware.AdminKey)
+	{
+		RegisterTenant(admin) // 租户管理
+	}
+
 	// apis
-	g := r.Group("/api/wf", customMiddleware.MultiTenant, customMiddleware.Auth)
+	g := r.Group("/api/wf", customMiddleware.Auth, customMiddleware.MultiTenant, customMiddleware.Idempotency)
 	{
 		RegisterProcessDefinition(g) // 流程定义
+		RegisterClassify(g)          // 流程分类
 		RegisterProcessInstance(g)   // 流程实例
+		RegisterTask(g)              // 待办任务
 		RegisterRoleUsers(g)         // 外部系统的角色用户映射
 	}
 
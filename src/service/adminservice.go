@@ -0,0 +1,174 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/19 11:05
+ * @Desc: 租户管理员对流程实例的特权操作, 所有操作都会记录到流转历史中
+ */
+package service
+
+import (
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"workflow/src/global"
+	"workflow/src/model"
+	"workflow/src/model/dto"
+	"workflow/src/model/request"
+	"workflow/src/service/engine"
+	"workflow/src/util"
+)
+
+// 强制跳转到指定节点
+func AdminJumpProcessInstance(r *request.AdminJumpRequest, c echo.Context) (*model.ProcessInstance, error) {
+	return runAdminOperation(r.ProcessInstanceId, c, func(processEngine *engine.ProcessEngine) error {
+		return processEngine.Jump(r.NodeId, r.Remarks)
+	})
+}
+
+// 修改当前节点的处理人
+func AdminReassignProcessInstance(r *request.AdminReassignRequest, c echo.Context) (*model.ProcessInstance, error) {
+	return runAdminOperation(r.ProcessInstanceId, c, func(processEngine *engine.ProcessEngine) error {
+		return processEngine.Reassign(r.NodeId, r.FromUser, r.ToUsers, r.Remarks)
+	})
+}
+
+// 终止流程
+func AdminTerminateProcessInstance(r *request.AdminTerminateRequest, c echo.Context) (*model.ProcessInstance, error) {
+	return runAdminOperation(r.ProcessInstanceId, c, func(processEngine *engine.ProcessEngine) error {
+		return processEngine.Terminate(r.Remarks)
+	})
+}
+
+// 删除流程实例(软删除), 未处理的待办任务会被取消
+func AdminDeleteProcessInstance(r *request.AdminDeleteRequest, c echo.Context) error {
+	tenantId, userIdentifier := util.GetWorkContext(c)
+	err := requireTenantAdmin(userIdentifier, tenantId)
+	if err != nil {
+		return err
+	}
+
+	return runAdminOnInstance(r.Id, false, userIdentifier, tenantId, func(processEngine *engine.ProcessEngine, tx *gorm.DB) error {
+		err := tx.Delete(&processEngine.ProcessInstance).Error
+		if err != nil {
+			return err
+		}
+
+		err = processEngine.SyncTasks(dto.StateArray{})
+		if err != nil {
+			return err
+		}
+
+		return processEngine.CreateAdminHistory(engine.AdminDelete, "", "", "", r.Remarks)
+	})
+}
+
+// 恢复被删除的流程实例, 当前节点的待办任务会重新生成
+func AdminUndeleteProcessInstance(r *request.AdminDeleteRequest, c echo.Context) error {
+	tenantId, userIdentifier := util.GetWorkContext(c)
+	err := requireTenantAdmin(userIdentifier, tenantId)
+	if err != nil {
+		return err
+	}
+
+	return runAdminOnInstance(r.Id, true, userIdentifier, tenantId, func(processEngine *engine.ProcessEngine, tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Model(&model.ProcessInstance{}).
+			Where("id = ?", processEngine.ProcessInstance.Id).
+			Update("deleted_at", nil).
+			Error
+		if err != nil {
+			return err
+		}
+
+		if !processEngine.ProcessInstance.IsEnd && !processEngine.ProcessInstance.IsDenied {
+			err = processEngine.SyncTasks(processEngine.ProcessInstance.State)
+			if err != nil {
+				return err
+			}
+		}
+
+		return processEngine.CreateAdminHistory(engine.AdminUndelete, "", "", "", r.Remarks)
+	})
+}
+
+// 当前用户必须是租户管理员
+func requireTenantAdmin(userIdentifier string, tenantId int) error {
+	if global.BankConfig.App.AdminRole == "" {
+		return util.Forbidden.New("未配置管理员角色, 不能进行管理员操作")
+	}
+
+	roles, err := GetUserRoleIdentifiers(userIdentifier, tenantId)
+	if err != nil {
+		return err
+	}
+	if !isTenantAdmin(roles) {
+		return util.Forbidden.New("只有管理员才能进行当前操作")
+	}
+
+	return nil
+}
+
+// 对进行中的流程实例进行管理员操作
+func runAdminOperation(instanceId int, c echo.Context, operate func(processEngine *engine.ProcessEngine) error) (*model.ProcessInstance, error) {
+	tenantId, userIdentifier := util.GetWorkContext(c)
+	err := requireTenantAdmin(userIdentifier, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	var processEngine *engine.ProcessEngine
+	err = retryOnConflict(func() error {
+		return runAdminOnInstance(instanceId, false, userIdentifier, tenantId, func(e *engine.ProcessEngine, tx *gorm.DB) error {
+			processEngine = e
+			return operate(e)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &processEngine.ProcessInstance, nil
+}
+
+// 在事务中对流程实例进行操作, deleted为true的时候只查找已删除的流程实例
+// 事务提交之后再推送事件
+func runAdminOnInstance(instanceId int, deleted bool, userIdentifier string, tenantId int, operate func(processEngine *engine.ProcessEngine, tx *gorm.DB) error) error {
+	var instance model.ProcessInstance
+	db := global.BankDb.
+		Where("id = ?", instanceId).
+		Where("tenant_id = ?", tenantId)
+	if deleted {
+		db = db.Unscoped().Where("deleted_at is not null")
+	}
+	err := db.First(&instance).Error
+	if err != nil {
+		return util.NotFound.New("记录不存在")
+	}
+
+	var definition model.ProcessDefinition
+	err = global.BankDb.
+		Where("id = ?", instance.ProcessDefinitionId).
+		Where("tenant_id = ?", tenantId).
+		First(&definition).
+		Error
+	if err != nil {
+		return util.NotFound.Newf("找不到当前processDefinitionId为 %v 的记录", instance.ProcessDefinitionId)
+	}
+
+	tx := global.BankDb.Begin() // 开启事务
+	processEngine, err := engine.NewProcessEngine(definition, instance, userIdentifier, tenantId, tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = operate(processEngine, tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	tx.Commit()
+	processEngine.PublishEvents()
+
+	return nil
+}
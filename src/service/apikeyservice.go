@@ -0,0 +1,87 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/18 14:20
+ * @Desc: 租户的api key
+ */
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"workflow/src/global"
+	"workflow/src/model"
+	"workflow/src/model/request"
+	"workflow/src/model/response"
+	"workflow/src/util"
+)
+
+// 创建api key
+func CreateApiKey(r *request.ApiKeyRequest) (*response.ApiKeyResponse, error) {
+	if _, err := GetTenant(r.TenantId); err != nil {
+		return nil, err
+	}
+	if r.Name == "" {
+		return nil, util.BadRequest.New("名称不能为空")
+	}
+	if r.UserIdentifier == "" && !r.AllowImpersonation {
+		return nil, util.BadRequest.New("用户标识不能为空")
+	}
+
+	random := make([]byte, 24)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	key := "wf_" + hex.EncodeToString(random)
+
+	apiKey := model.ApiKey{
+		Name:               r.Name,
+		Prefix:             key[:11],
+		KeyHash:            util.Sha256Hex(key), // 只保存sha256, 不保存明文
+		UserIdentifier:     r.UserIdentifier,
+		AllowImpersonation: r.AllowImpersonation,
+		ExpireTime:         r.ExpireTime,
+		TenantId:           r.TenantId,
+		CreateTime:         time.Now().Local(),
+	}
+	err := global.BankDb.Create(&apiKey).Error
+	if err != nil {
+		global.BankLogger.Error(err)
+		return nil, util.NewError("创建失败")
+	}
+
+	return &response.ApiKeyResponse{
+		ApiKey: apiKey,
+		Key:    key,
+	}, nil
+}
+
+// 获取租户的api key列表
+func ListApiKeys(tenantId int) ([]model.ApiKey, error) {
+	var apiKeys []model.ApiKey
+	err := global.BankDb.
+		Where("tenant_id = ?", tenantId).
+		Order("id").
+		Find(&apiKeys).
+		Error
+
+	return apiKeys, err
+}
+
+// 禁用api key
+func DisableApiKey(tenantId int, id int) error {
+	result := global.BankDb.
+		Model(&model.ApiKey{}).
+		Where("id = ?", id).
+		Where("tenant_id = ?", tenantId).
+		Update("is_disabled", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return util.NotFound.New("api key不存在")
+	}
+
+	return nil
+}
@@ -0,0 +1,301 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/15 10:05
+ * @Desc: 流程分类
+ */
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"workflow/src/global"
+	"workflow/src/model"
+	"workflow/src/model/request"
+	"workflow/src/model/response"
+	"workflow/src/util"
+)
+
+// 获取分类详情
+func GetClassify(id int, tenantId int) (*model.Classify, error) {
+	var classify model.Classify
+
+	err := global.BankDb.
+		Where("id = ?", id).
+		Where("tenant_id = ?", tenantId).
+		First(&classify).
+		Error
+	if err != nil {
+		return nil, util.NotFound.New("分类不存在")
+	}
+
+	return &classify, nil
+}
+
+// 验证
+func ValidateClassifyRequest(r *request.ClassifyRequest, excludeId int, tenantId int) error {
+	if r.Name == "" {
+		return util.BadRequest.New("分类名称不能为空")
+	}
+
+	// 同一个父分类下名称不能重复
+	var c int64
+	global.BankDb.Model(&model.Classify{}).
+		Where("name = ?", r.Name).
+		Where("parent_id = ?", r.ParentId).
+		Where("id != ?", excludeId).
+		Where("tenant_id = ?", tenantId).
+		Count(&c)
+	if c != 0 {
+		return util.BadRequest.Newf("当前名称为:\"%s\"的分类已存在", r.Name)
+	}
+
+	if r.ParentId == 0 {
+		return nil
+	}
+
+	// 父分类必须存在, 且不能是自己或者自己的子分类
+	classifies, err := listClassifies(tenantId)
+	if err != nil {
+		return err
+	}
+	parents := make(map[int]int, len(classifies))
+	for _, classify := range classifies {
+		parents[classify.Id] = classify.ParentId
+	}
+	if _, exist := parents[r.ParentId]; !exist {
+		return util.BadRequest.New("父分类不存在")
+	}
+	if excludeId != 0 {
+		for id := r.ParentId; id != 0; id = parents[id] {
+			if id == excludeId {
+				return util.BadRequest.New("父分类不能是当前分类或者当前分类的子分类")
+			}
+		}
+	}
+
+	return nil
+}
+
+// 创建分类
+func CreateClassify(r *request.ClassifyRequest, c echo.Context) (*model.Classify, error) {
+	tenantId, userIdentifier := util.GetWorkContext(c)
+	classify := r.ToClassify(userIdentifier, tenantId)
+	classify.Id = 0
+
+	err := global.BankDb.Create(&classify).Error
+	if err != nil {
+		global.BankLogger.Error(err)
+		return nil, util.NewError("创建失败")
+	}
+
+	return &classify, nil
+}
+
+// 更新分类
+func UpdateClassify(r *request.ClassifyRequest, c echo.Context) error {
+	tenantId, userIdentifier := util.GetWorkContext(c)
+
+	_, err := GetClassify(r.Id, tenantId)
+	if err != nil {
+		return err
+	}
+
+	err = global.BankDb.
+		Model(&model.Classify{}).
+		Where("id = ?", r.Id).
+		Where("tenant_id = ?", tenantId).
+		Updates(map[string]interface{}{
+			"name":        r.Name,
+			"parent_id":   r.ParentId,
+			"sort":        r.Sort,
+			"update_by":   userIdentifier,
+			"update_time": time.Now().Local(),
+		}).Error
+
+	return err
+}
+
+// 删除分类, 有子分类或者还在被流程定义/实例使用的不能删除
+func DeleteClassify(id int, tenantId int) error {
+	_, err := GetClassify(id, tenantId)
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	global.BankDb.Model(&model.Classify{}).
+		Where("parent_id = ?", id).
+		Where("tenant_id = ?", tenantId).
+		Count(&count)
+	if count != 0 {
+		return util.BadRequest.New("当前分类下还有子分类, 不能删除")
+	}
+
+	global.BankDb.Model(&model.ProcessDefinition{}).
+		Where("classify_id = ?", id).
+		Where("tenant_id = ?", tenantId).
+		Count(&count)
+	if count != 0 {
+		return util.BadRequest.Newf("当前分类还有%d个流程定义在使用, 不能删除", count)
+	}
+
+	global.BankDb.Model(&model.ProcessInstance{}).
+		Where("classify_id = ?", id).
+		Where("tenant_id = ?", tenantId).
+		Count(&count)
+	if count != 0 {
+		return util.BadRequest.Newf("当前分类还有%d个流程实例在使用, 不能删除", count)
+	}
+
+	return global.BankDb.
+		Where("id = ?", id).
+		Where("tenant_id = ?", tenantId).
+		Delete(&model.Classify{}).
+		Error
+}
+
+// 获取分类树
+func GetClassifyTree(tenantId int) ([]response.ClassifyTreeNode, error) {
+	classifies, err := listClassifies(tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	children := groupClassifiesByParent(classifies)
+	var build func(parentId int) []response.ClassifyTreeNode
+	build = func(parentId int) []response.ClassifyTreeNode {
+		nodes := make([]response.ClassifyTreeNode, 0, len(children[parentId]))
+		for _, classify := range children[parentId] {
+			nodes = append(nodes, response.ClassifyTreeNode{
+				Classify: classify,
+				Children: build(classify.Id),
+			})
+		}
+		return nodes
+	}
+
+	return build(0), nil
+}
+
+// 按分类分组获取当前用户可以发起的流程定义(发起流程的页面使用)
+// 没有分类的流程定义放在id为0的"未分类"分组中, 没有流程定义的分类不返回
+func GetGroupedDefinitions(r *request.GroupedDefinitionRequest, c echo.Context) ([]response.ClassifyDefinitionGroup, error) {
+	tenantId, userIdentifier := util.GetWorkContext(c)
+	classifies, err := listClassifies(tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 只返回当前用户可以发起的流程
+	var definitions []model.ProcessDefinition
+	db, err := applyStarterFilter(global.BankDb.Model(&model.ProcessDefinition{}), userIdentifier, tenantId)
+	if err != nil {
+		return nil, err
+	}
+	db = db.Where("tenant_id = ?", tenantId)
+	if r.Keyword != "" {
+		if err := util.ValidateKeyword(r.Keyword); err != nil {
+			return nil, err
+		}
+		db = db.Where("name ~ ?", r.Keyword)
+	}
+	err = db.
+		Omit("structure").
+		Order("name").
+		Find(&definitions).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 不存在的分类当作未分类
+	classifyIds := make(map[int]bool, len(classifies))
+	for _, classify := range classifies {
+		classifyIds[classify.Id] = true
+	}
+	definitionMap := make(map[int][]model.ProcessDefinition)
+	for _, definition := range definitions {
+		classifyId := definition.ClassifyId
+		if !classifyIds[classifyId] {
+			classifyId = 0
+		}
+		definitionMap[classifyId] = append(definitionMap[classifyId], definition)
+	}
+
+	children := groupClassifiesByParent(classifies)
+	var build func(parentId int) []response.ClassifyDefinitionGroup
+	build = func(parentId int) []response.ClassifyDefinitionGroup {
+		groups := make([]response.ClassifyDefinitionGroup, 0)
+		for _, classify := range children[parentId] {
+			group := response.ClassifyDefinitionGroup{
+				Classify:    classify,
+				Definitions: definitionMap[classify.Id],
+				Children:    build(classify.Id),
+			}
+			if len(group.Definitions) == 0 && len(group.Children) == 0 {
+				continue
+			}
+			if group.Definitions == nil {
+				group.Definitions = []model.ProcessDefinition{}
+			}
+			groups = append(groups, group)
+		}
+		return groups
+	}
+
+	groups := build(0)
+	if len(definitionMap[0]) > 0 {
+		groups = append(groups, response.ClassifyDefinitionGroup{
+			Classify:    model.Classify{Name: "未分类", TenantId: tenantId},
+			Definitions: definitionMap[0],
+			Children:    []response.ClassifyDefinitionGroup{},
+		})
+	}
+
+	return groups, nil
+}
+
+func listClassifies(tenantId int) ([]model.Classify, error) {
+	var classifies []model.Classify
+	err := global.BankDb.
+		Where("tenant_id = ?", tenantId).
+		Order("sort").
+		Order("id").
+		Find(&classifies).
+		Error
+	if err != nil {
+		global.BankLogger.Error(err)
+		return nil, util.NewError("查询分类失败")
+	}
+
+	return classifies, nil
+}
+
+// 按父分类分组, 父分类不存在的当作顶级分类
+func groupClassifiesByParent(classifies []model.Classify) map[int][]model.Classify {
+	exist := make(map[int]bool, len(classifies))
+	for _, classify := range classifies {
+		exist[classify.Id] = true
+	}
+
+	children := make(map[int][]model.Classify)
+	for _, classify := range classifies {
+		parentId := classify.ParentId
+		if !exist[parentId] {
+			parentId = 0
+		}
+		children[parentId] = append(children[parentId], classify)
+	}
+
+	for parentId := range children {
+		list := children[parentId]
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].Sort < list[j].Sort
+		})
+	}
+
+	return children
+}
@@ -0,0 +1,276 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/12 11:30
+ * @Desc: 流程定义对比
+ */
+package service
+
+import (
+	"fmt"
+	"reflect"
+
+	"workflow/src/global"
+	"workflow/src/global/shared"
+	"workflow/src/model"
+	"workflow/src/model/dto"
+	"workflow/src/model/request"
+	"workflow/src/model/response"
+	"workflow/src/util"
+)
+
+// 对比两个流程定义(或者同一个流程定义的两个版本)
+func DiffDefinition(r *request.DefinitionDiffRequest, tenantId int) (*response.DefinitionDiffResponse, error) {
+	if r.SourceId == 0 {
+		return nil, util.BadRequest.New("sourceId不能为空")
+	}
+	if r.TargetId == 0 {
+		r.TargetId = r.SourceId
+	}
+
+	source, sourceStructure, err := getDefinitionSnapshot(r.SourceId, r.SourceVersion, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	target, targetStructure, err := getDefinitionSnapshot(r.TargetId, r.TargetVersion, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeDiff, edgeDiff, summary := DiffStructure(sourceStructure, targetStructure)
+
+	return &response.DefinitionDiffResponse{
+		Source:  source,
+		Target:  target,
+		Nodes:   nodeDiff,
+		Edges:   edgeDiff,
+		Summary: summary,
+	}, nil
+}
+
+// 获取流程定义的历史版本列表
+func ListDefinitionVersions(r *request.DefinitionVersionListRequest, tenantId int) (*response.PagingResponse, error) {
+	var versions []model.ProcessDefinitionVersion
+
+	err := shared.ResolveSort(&r.PagingRequest, map[string]string{
+		"":            "version",
+		"version":     "version",
+		"create_time": "create_time",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	db := global.BankDb.
+		Model(&model.ProcessDefinitionVersion{}).
+		Where("process_definition_id = ?", r.Id).
+		Where("tenant_id = ?", tenantId)
+
+	var count int64
+	db.Count(&count)
+
+	db = shared.ApplyPaging(db, &r.PagingRequest)
+	err = db.Omit("structure").Find(&versions).Error
+
+	return &response.PagingResponse{
+		TotalCount:   count,
+		CurrentCount: int64(len(versions)),
+		Data:         &versions,
+	}, err
+}
+
+// 获取流程定义某个版本的结构, version为0或者等于当前版本的时候取当前的流程定义
+func getDefinitionSnapshot(id int, version int, tenantId int) (response.DiffDefinition, dto.Structure, error) {
+	definition, err := GetDefinition(id, tenantId)
+	if err != nil {
+		return response.DiffDefinition{}, dto.Structure{}, util.NotFound.Newf("当前id为%d的流程定义不存在", id)
+	}
+
+	if version == 0 || version == definition.Version {
+		return response.DiffDefinition{
+			Id:      definition.Id,
+			Version: definition.Version,
+			Name:    definition.Name,
+		}, definition.Structure, nil
+	}
+
+	var snapshot model.ProcessDefinitionVersion
+	err = global.BankDb.
+		Where("process_definition_id = ?", id).
+		Where("version = ?", version).
+		Where("tenant_id = ?", tenantId).
+		First(&snapshot).
+		Error
+	if err != nil {
+		return response.DiffDefinition{}, dto.Structure{}, util.NotFound.Newf("流程定义%d不存在版本%d", id, version)
+	}
+
+	return response.DiffDefinition{
+		Id:      id,
+		Version: snapshot.Version,
+		Name:    snapshot.Name,
+	}, snapshot.Structure, nil
+}
+
+// 对比两个流程结构, 节点和顺序流都按照id进行匹配
+// 坐标、尺寸等只影响展示的字段不参与对比
+func DiffStructure(source dto.Structure, target dto.Structure) (response.NodeDiff, response.EdgeDiff, []string) {
+	nodeDiff := response.NodeDiff{
+		Added:   []dto.Node{},
+		Removed: []dto.Node{},
+		Changed: []response.ItemChange{},
+	}
+	edgeDiff := response.EdgeDiff{
+		Added:   []dto.Edge{},
+		Removed: []dto.Edge{},
+		Changed: []response.ItemChange{},
+	}
+	summary := make([]string, 0)
+
+	// 节点
+	sourceNodes := make(map[string]dto.Node, len(source.Nodes))
+	for _, node := range source.Nodes {
+		sourceNodes[node.Id] = node
+	}
+	targetNodes := make(map[string]dto.Node, len(target.Nodes))
+	for _, node := range target.Nodes {
+		targetNodes[node.Id] = node
+	}
+
+	for _, node := range source.Nodes {
+		if _, exist := targetNodes[node.Id]; !exist {
+			nodeDiff.Removed = append(nodeDiff.Removed, node)
+			summary = append(summary, fmt.Sprintf("删除节点: %s", nodeName(node)))
+		}
+	}
+	for _, node := range target.Nodes {
+		origin, exist := sourceNodes[node.Id]
+		if !exist {
+			nodeDiff.Added = append(nodeDiff.Added, node)
+			summary = append(summary, fmt.Sprintf("新增节点: %s", nodeName(node)))
+			continue
+		}
+
+		changes := diffNode(origin, node)
+		if len(changes) == 0 {
+			continue
+		}
+		nodeDiff.Changed = append(nodeDiff.Changed, response.ItemChange{Id: node.Id, Label: node.Label, Changes: changes})
+		for _, change := range changes {
+			summary = append(summary, fmt.Sprintf("节点 %s 的%s由 %v 变为 %v", nodeName(node), diffFieldNames[change.Field], change.OldValue, change.NewValue))
+		}
+	}
+
+	// 顺序流
+	sourceEdges := make(map[string]dto.Edge, len(source.Edges))
+	for _, edge := range source.Edges {
+		sourceEdges[edge.Id] = edge
+	}
+	targetEdges := make(map[string]dto.Edge, len(target.Edges))
+	for _, edge := range target.Edges {
+		targetEdges[edge.Id] = edge
+	}
+
+	for _, edge := range source.Edges {
+		if _, exist := targetEdges[edge.Id]; !exist {
+			edgeDiff.Removed = append(edgeDiff.Removed, edge)
+			summary = append(summary, fmt.Sprintf("删除顺序流: %s", edgeName(edge)))
+		}
+	}
+	for _, edge := range target.Edges {
+		origin, exist := sourceEdges[edge.Id]
+		if !exist {
+			edgeDiff.Added = append(edgeDiff.Added, edge)
+			summary = append(summary, fmt.Sprintf("新增顺序流: %s", edgeName(edge)))
+			continue
+		}
+
+		changes := diffEdge(origin, edge)
+		if len(changes) == 0 {
+			continue
+		}
+		edgeDiff.Changed = append(edgeDiff.Changed, response.ItemChange{Id: edge.Id, Label: edge.Label, Changes: changes})
+		for _, change := range changes {
+			summary = append(summary, fmt.Sprintf("顺序流 %s 的%s由 %v 变为 %v", edgeName(edge), diffFieldNames[change.Field], change.OldValue, change.NewValue))
+		}
+	}
+
+	return nodeDiff, edgeDiff, summary
+}
+
+// 对比字段在summary中的中文名称
+var diffFieldNames = map[string]string{
+	"label":               "名称",
+	"clazz":               "类型",
+	"assignType":          "处理人类型",
+	"assignValue":         "处理人",
+	"isCounterSign":       "会签",
+	"isHideNode":          "隐藏",
+	"activeOrder":         "顺序处理",
+	"source":              "起点",
+	"target":              "终点",
+	"sort":                "排序",
+	"flowProperties":      "流向属性",
+	"conditionExpression": "条件表达式",
+}
+
+func diffNode(origin dto.Node, current dto.Node) []response.FieldChange {
+	changes := make([]response.FieldChange, 0)
+	appendFieldChange(&changes, "label", origin.Label, current.Label)
+	appendFieldChange(&changes, "clazz", origin.Clazz, current.Clazz)
+	appendFieldChange(&changes, "assignType", origin.AssignType, current.AssignType)
+	appendFieldChange(&changes, "assignValue", origin.AssignValue, current.AssignValue)
+	appendFieldChange(&changes, "assignBackup", origin.AssignBackup, current.AssignBackup)
+	appendFieldChange(&changes, "isCounterSign", origin.IsCounterSign, current.IsCounterSign)
+	appendFieldChange(&changes, "ccUsers", origin.CcUsers, current.CcUsers)
+	appendFieldChange(&changes, "ccRoles", origin.CcRoles, current.CcRoles)
+	appendFieldChange(&changes, "isHideNode", origin.IsHideNode, current.IsHideNode)
+	appendFieldChange(&changes, "activeOrder", origin.ActiveOrder, current.ActiveOrder)
+
+	return changes
+}
+
+func diffEdge(origin dto.Edge, current dto.Edge) []response.FieldChange {
+	changes := make([]response.FieldChange, 0)
+	appendFieldChange(&changes, "label", origin.Label, current.Label)
+	appendFieldChange(&changes, "source", origin.Source, current.Source)
+	appendFieldChange(&changes, "target", origin.Target, current.Target)
+	appendFieldChange(&changes, "sort", origin.Sort, current.Sort)
+	appendFieldChange(&changes, "flowProperties", origin.FlowProperties, current.FlowProperties)
+	appendFieldChange(&changes, "conditionExpression", origin.ConditionExpression, current.ConditionExpression)
+	appendFieldChange(&changes, "isDefault", origin.IsDefault, current.IsDefault)
+
+	return changes
+}
+
+func appendFieldChange(changes *[]response.FieldChange, field string, oldValue interface{}, newValue interface{}) {
+	// nil和空数组当作相同
+	if oldSlice, ok := oldValue.([]string); ok && len(oldSlice) == 0 {
+		oldValue = []string{}
+	}
+	if newSlice, ok := newValue.([]string); ok && len(newSlice) == 0 {
+		newValue = []string{}
+	}
+
+	if reflect.DeepEqual(oldValue, newValue) {
+		return
+	}
+
+	*changes = append(*changes, response.FieldChange{
+		Field:    field,
+		OldValue: oldValue,
+		NewValue: newValue,
+	})
+}
+
+func nodeName(node dto.Node) string {
+	return fmt.Sprintf("%s(%s)", node.Label, node.Id)
+}
+
+func edgeName(edge dto.Edge) string {
+	if edge.Label == "" {
+		return fmt.Sprintf("%s -> %s(%s)", edge.Source, edge.Target, edge.Id)
+	}
+
+	return fmt.Sprintf("%s: %s -> %s(%s)", edge.Label, edge.Source, edge.Target, edge.Id)
+}
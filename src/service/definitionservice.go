@@ -9,23 +9,36 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"workflow/src/global"
 	"workflow/src/global/constant"
 	"workflow/src/global/shared"
 	"workflow/src/model"
+	"workflow/src/model/dto"
 	"workflow/src/model/request"
 	"workflow/src/model/response"
+	"workflow/src/service/diagram"
 	"workflow/src/util"
 )
 
+// 获取流程定义, 优先从缓存中获取, 流程定义修改之后会通知所有副本使缓存失效
+// 提交统计(submitCount)只是统计字段, 创建流程实例的时候不会使缓存失效, 需要最新值的时候使用GetDefinitionDetail
 func GetDefinition(id int, tenantId int) (*model.ProcessDefinition, error) {
-	var definition model.ProcessDefinition
+	cacheKey := shared.DefinitionCacheKey(tenantId, id)
+	if cached, ok := global.BankCache.Get(cacheKey); ok {
+		definition := cached.(model.ProcessDefinition)
+		return &definition, nil
+	}
 
+	var definition model.ProcessDefinition
 	err := global.BankDb.
 		Where("id=?", id).
 		Where("tenant_id=?", tenantId).
@@ -34,10 +47,31 @@ func GetDefinition(id int, tenantId int) (*model.ProcessDefinition, error) {
 		global.BankLogger.Error(err)
 		return nil, util.NewError("查询流程详情失败")
 	}
+	global.BankCache.SetDefault(cacheKey, definition)
 
 	return &definition, nil
 }
 
+// 获取流程定义详情, 提交统计不使用缓存, 每次读取最新值
+func GetDefinitionDetail(id int, tenantId int) (*model.ProcessDefinition, error) {
+	definition, err := GetDefinition(id, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = global.BankDb.Model(&model.ProcessDefinition{}).
+		Where("id = ?", id).
+		Select("submit_count").
+		Scan(&definition.SubmitCount).
+		Error
+	if err != nil {
+		global.BankLogger.Error(err)
+		return nil, util.NewError("查询流程详情失败")
+	}
+
+	return definition, nil
+}
+
 // 验证
 func ValidateDefinitionRequest(r *request.ProcessDefinitionRequest, excludeId int, tenantId int) error {
 	// 验证名称是否已存在
@@ -51,6 +85,13 @@ func ValidateDefinitionRequest(r *request.ProcessDefinitionRequest, excludeId in
 		return util.BadRequest.Newf("当前名称为:\"%s\"的模板已存在", r.Name)
 	}
 
+	// 验证分类是否存在
+	if r.ClassifyId != 0 {
+		if _, err := GetClassify(r.ClassifyId, tenantId); err != nil {
+			return util.BadRequest.Newf("当前id为%d的分类不存在", r.ClassifyId)
+		}
+	}
+
 	// 如果edge对象不存在id，则生成一个
 	var definitionStructure map[string][]map[string]interface{}
 	err := json.Unmarshal(r.Structure, &definitionStructure)
@@ -65,7 +106,58 @@ func ValidateDefinitionRequest(r *request.ProcessDefinitionRequest, excludeId in
 	}
 	r.Structure = util.MarshalToBytes(definitionStructure)
 
-	// todo 校验structure的json
+	// 校验默认流向
+	var structure dto.Structure
+	if err = json.Unmarshal(r.Structure, &structure); err != nil {
+		return util.BadRequest.New("当前structure不合法，请检查")
+	}
+	if err = validateDefaultEdges(structure); err != nil {
+		return err
+	}
+
+	// 处理人来自变量的节点必须配置变量名或者表达式, 第N级上级必须配置级数
+	for _, node := range structure.Nodes {
+		if node.AssignType == "variable" && len(node.AssignValue) == 0 {
+			return util.BadRequest.Newf("节点%s的处理人类型为variable, 需要在assignValue中配置变量名或者表达式", node.Id)
+		}
+		if node.AssignType == "initiatorNthManager" {
+			if len(node.AssignValue) == 0 {
+				return util.BadRequest.Newf("节点%s的处理人类型为initiatorNthManager, 需要在assignValue中配置上级的级数", node.Id)
+			}
+			if level, err := strconv.Atoi(node.AssignValue[0]); err != nil || level <= 0 {
+				return util.BadRequest.Newf("节点%s的上级级数必须是正整数, 当前为: %s", node.Id, node.AssignValue[0])
+			}
+		}
+	}
+
+	// todo 校验structure的其他内容
+
+	return nil
+}
+
+// 默认流向只能从排他网关或者用户任务出发, 并且每个节点最多只能有一个默认流向
+func validateDefaultEdges(structure dto.Structure) error {
+	nodes := make(map[string]dto.Node, len(structure.Nodes))
+	for _, node := range structure.Nodes {
+		nodes[node.Id] = node
+	}
+
+	defaultEdges := make(map[string]string)
+	for _, edge := range structure.Edges {
+		if !edge.IsDefault {
+			continue
+		}
+
+		source, ok := nodes[edge.Source]
+		if !ok || (source.Clazz != constant.ExclusiveGateway && source.Clazz != constant.UserTask) {
+			return util.BadRequest.Newf("顺序流%s不是从排他网关或者用户任务出发的, 不能设置为默认流向", edge.Id)
+		}
+
+		if exist, ok := defaultEdges[edge.Source]; ok {
+			return util.BadRequest.Newf("节点%s最多只能有一个默认流向, 当前有%s和%s", edge.Source, exist, edge.Id)
+		}
+		defaultEdges[edge.Source] = edge.Id
+	}
 
 	return nil
 }
@@ -74,17 +166,29 @@ func ValidateDefinitionRequest(r *request.ProcessDefinitionRequest, excludeId in
 func CreateDefinition(r *request.ProcessDefinitionRequest, c echo.Context) (*model.ProcessDefinition, error) {
 	var (
 		processDefinition        = r.ProcessDefinition()
+		tx                       = global.BankDb.Begin() // 开启事务
 		tenantId, userIdentifier = util.GetWorkContext(c)
 	)
 	processDefinition.CreateBy = userIdentifier
 	processDefinition.UpdateBy = userIdentifier
 	processDefinition.TenantId = tenantId
+	processDefinition.Version = 1
+
+	err := tx.Create(&processDefinition).Error
+	if err != nil {
+		tx.Rollback()
+		log.Error(err)
+		return nil, util.NewError("创建失败")
+	}
 
-	err := global.BankDb.Create(&processDefinition).Error
+	// 保存第一个版本的快照
+	err = createDefinitionVersion(tx, processDefinition, userIdentifier)
 	if err != nil {
+		tx.Rollback()
 		log.Error(err)
 		return nil, util.NewError("创建失败")
 	}
+	tx.Commit()
 
 	return &processDefinition, nil
 }
@@ -93,35 +197,80 @@ func CreateDefinition(r *request.ProcessDefinitionRequest, c echo.Context) (*mod
 func UpdateDefinition(r *request.ProcessDefinitionRequest, c echo.Context) error {
 	var (
 		processDefinition        = r.ProcessDefinition()
+		originDefinition         model.ProcessDefinition
 		tenantId, userIdentifier = util.GetWorkContext(c)
 	)
 
-	// 先查询
-	var count int64
-	err := global.BankDb.Model(&model.ProcessDefinition{}).
+	tx := global.BankDb.Begin()
+
+	// 先查询, 锁住流程定义的行, 避免并发修改的时候生成相同的版本号
+	err := tx.Model(&model.ProcessDefinition{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
 		Where("id=?", processDefinition.Id).
 		Where("tenant_id=?", tenantId).
-		Count(&count).
+		Select("id, version").
+		First(&originDefinition).
 		Error
-	if err != nil || count == 0 {
+	if err != nil {
+		tx.Rollback()
 		return util.NotFound.New("记录不存在")
 	}
+	processDefinition.Version = originDefinition.Version + 1
+	processDefinition.TenantId = tenantId
 
-	err = global.BankDb.
+	err = tx.
 		Model(&processDefinition).
 		Updates(map[string]interface{}{
-			"name":        processDefinition.Name,
-			"form_id":     processDefinition.FormId,
-			"structure":   processDefinition.Structure,
-			"classify_id": processDefinition.ClassifyId,
-			"task":        processDefinition.Task,
-			"notice":      processDefinition.Notice,
-			"remarks":     processDefinition.Remarks,
-			"update_by":   userIdentifier,
-			"update_time": time.Now().Local(),
+			"name":            processDefinition.Name,
+			"form_id":         processDefinition.FormId,
+			"structure":       processDefinition.Structure,
+			"classify_id":     processDefinition.ClassifyId,
+			"task":            processDefinition.Task,
+			"notice":          processDefinition.Notice,
+			"remarks":         processDefinition.Remarks,
+			"version":         processDefinition.Version,
+			"starter_users":   processDefinition.StarterUsers,
+			"starter_roles":   processDefinition.StarterRoles,
+			"viewer_users":    processDefinition.ViewerUsers,
+			"viewer_roles":    processDefinition.ViewerRoles,
+			"viewer_managers": processDefinition.ViewerManagers,
+			"update_by":       userIdentifier,
+			"update_time":     time.Now().Local(),
 		}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 保存修改之后的版本快照
+	err = createDefinitionVersion(tx, processDefinition, userIdentifier)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 
-	return err
+	err = tx.Commit().Error
+	if err != nil {
+		return err
+	}
+	shared.InvalidateCache(shared.DefinitionCacheKey(tenantId, processDefinition.Id))
+
+	return nil
+}
+
+// 保存流程定义的版本快照
+func createDefinitionVersion(tx *gorm.DB, definition model.ProcessDefinition, userIdentifier string) error {
+	version := model.ProcessDefinitionVersion{
+		ProcessDefinitionId: definition.Id,
+		Version:             definition.Version,
+		Name:                definition.Name,
+		Structure:           definition.Structure,
+		TenantId:            definition.TenantId,
+		CreateBy:            userIdentifier,
+		CreateTime:          time.Now().Local(),
+	}
+
+	return tx.Model(&model.ProcessDefinitionVersion{}).Create(&version).Error
 }
 
 // 删除流程定义
@@ -142,16 +291,32 @@ func DeleteDefinition(id int, tenantId int) error {
 	if err != nil {
 		return errors.New("流程不存在")
 	}
+	shared.InvalidateCache(shared.DefinitionCacheKey(tenantId, id))
 
 	return nil
 }
 
+// 流程定义列表可用的排序字段
+var definitionSortColumns = map[string]string{
+	"":             "update_time",
+	"id":           "id",
+	"name":         "name",
+	"create_time":  "create_time",
+	"update_time":  "update_time",
+	"submit_count": "submit_count",
+}
+
 func GetDefinitionList(r *request.DefinitionListRequest, c echo.Context) (interface{}, error) {
 	var (
 		definitions              []model.ProcessDefinition
 		tenantId, userIdentifier = util.GetWorkContext(c)
 	)
 
+	err := shared.ResolveSort(&r.PagingRequest, definitionSortColumns)
+	if err != nil {
+		return nil, err
+	}
+
 	db := global.BankDb.Model(&model.ProcessDefinition{}).Where("tenant_id = ?", tenantId)
 
 	// 根据type的不同有不同的逻辑
@@ -166,6 +331,9 @@ func GetDefinitionList(r *request.DefinitionListRequest, c echo.Context) (interf
 	}
 
 	if r.Keyword != "" {
+		if err := util.ValidateKeyword(r.Keyword); err != nil {
+			return nil, err
+		}
 		db = db.Where("name ~ ?", r.Keyword)
 	}
 
@@ -173,7 +341,7 @@ func GetDefinitionList(r *request.DefinitionListRequest, c echo.Context) (interf
 	db.Count(&count)
 
 	db = shared.ApplyPaging(db, &r.PagingRequest)
-	err := db.Find(&definitions).Error
+	err = db.Find(&definitions).Error
 
 	return &response.PagingResponse{
 		TotalCount:   count,
@@ -182,6 +350,88 @@ func GetDefinitionList(r *request.DefinitionListRequest, c echo.Context) (interf
 	}, err
 }
 
+// 获取当前用户可以发起的流程定义列表
+func ListStartableDefinitions(r *request.StartableDefinitionRequest, c echo.Context) ([]model.ProcessDefinition, error) {
+	var (
+		definitions              []model.ProcessDefinition
+		tenantId, userIdentifier = util.GetWorkContext(c)
+	)
+
+	db, err := applyStarterFilter(global.BankDb.Model(&model.ProcessDefinition{}), userIdentifier, tenantId)
+	if err != nil {
+		return nil, err
+	}
+	db = db.Where("tenant_id = ?", tenantId)
+	if r.Keyword != "" {
+		if err := util.ValidateKeyword(r.Keyword); err != nil {
+			return nil, err
+		}
+		db = db.Where("name ~ ?", r.Keyword)
+	}
+
+	err = db.
+		Omit("structure").
+		Order("name").
+		Find(&definitions).
+		Error
+
+	return definitions, err
+}
+
+// 检查用户是否可以发起当前流程
+// StarterUsers和StarterRoles都为空的时候所有人都可以发起
+func CheckDefinitionStarter(definition *model.ProcessDefinition, userIdentifier string, tenantId int) error {
+	if len(definition.StarterUsers) == 0 && len(definition.StarterRoles) == 0 {
+		return nil
+	}
+
+	if util.SliceAnyString(definition.StarterUsers, userIdentifier) {
+		return nil
+	}
+
+	if len(definition.StarterRoles) > 0 {
+		roles, err := GetUserRoleIdentifiers(userIdentifier, tenantId)
+		if err != nil {
+			return err
+		}
+		for _, role := range roles {
+			if util.SliceAnyString(definition.StarterRoles, role) {
+				return nil
+			}
+		}
+	}
+
+	return util.Forbidden.Newf("当前用户没有权限发起流程:\"%s\"", definition.Name)
+}
+
+// 过滤出当前用户可以发起的流程定义
+func applyStarterFilter(db *gorm.DB, userIdentifier string, tenantId int) (*gorm.DB, error) {
+	roles, err := GetUserRoleIdentifiers(userIdentifier, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.Where("(cardinality(starter_users) = 0 and cardinality(starter_roles) = 0) or ? = any(starter_users) or starter_roles && ?",
+		userIdentifier, pq.StringArray(roles)), nil
+}
+
+// 导出流程定义为mermaid或者graphviz dot格式
+func ExportDefinition(id int, format string, tenantId int) (string, error) {
+	definition, err := GetDefinition(id, tenantId)
+	if err != nil {
+		return "", err
+	}
+
+	switch format {
+	case "mermaid":
+		return diagram.RenderMermaid(definition.Structure), nil
+	case "dot":
+		return diagram.RenderDot(definition.Name, definition.Structure), nil
+	default:
+		return "", util.BadRequest.New("format不合法, 可选值为mermaid或者dot")
+	}
+}
+
 func CloneDefinition(r *request.CloneDefinitionRequest, c echo.Context) (*model.ProcessDefinition, error) {
 	var (
 		tenantId, _ = util.GetWorkContext(c)
@@ -192,7 +442,7 @@ func CloneDefinition(r *request.CloneDefinitionRequest, c echo.Context) (*model.
 	}
 
 	newD := *definition
-	fmt.Printf("xinde: %p,jiude: %p,san:%p", &newD, definition,&*definition)
+	fmt.Printf("xinde: %p,jiude: %p,san:%p", &newD, definition, &*definition)
 
 	return nil, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,81 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/11 11:05
+ * @Desc: 流程模板导出为graphviz的dot格式
+ */
+package diagram
+
+import (
+	"fmt"
+	"strings"
+
+	"workflow/src/global/constant"
+	"workflow/src/model/dto"
+)
+
+// 导出graphviz的dot
+func RenderDot(name string, structure dto.Structure) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph \"%s\" {\n", dotEscape(name))
+	b.WriteString("    rankdir=TB;\n")
+	b.WriteString("    node [fontname=\"sans-serif\", fontsize=12];\n")
+	b.WriteString("    edge [fontname=\"sans-serif\", fontsize=10];\n")
+
+	// 节点
+	for _, node := range structure.Nodes {
+		label := node.Label
+		if label == "" {
+			label = node.Id
+		}
+
+		attrs := []string{fmt.Sprintf("label=\"%s\"", dotEscape(label))}
+		styles := make([]string, 0)
+		switch {
+		case node.Clazz == constant.START:
+			attrs = append(attrs, "shape=circle")
+		case node.Clazz == constant.End:
+			attrs = append(attrs, "shape=doublecircle")
+		case isGateway(node):
+			attrs = append(attrs, "shape=diamond", "fillcolor=\"#fffbe6\"", "color=\"#faad14\"")
+			styles = append(styles, "filled")
+		case node.IsCounterSign:
+			attrs = append(attrs, "shape=box", "fillcolor=\"#e6f7ff\"", "color=\"#1890ff\"", "peripheries=2")
+			styles = append(styles, "rounded", "filled")
+		default:
+			attrs = append(attrs, "shape=box")
+			styles = append(styles, "rounded")
+		}
+
+		// 隐藏节点用虚线表示
+		if node.IsHideNode {
+			attrs = append(attrs, "fontcolor=\"#8c8c8c\"")
+			styles = append(styles, "dashed")
+		}
+		if len(styles) > 0 {
+			attrs = append(attrs, fmt.Sprintf("style=\"%s\"", strings.Join(styles, ",")))
+		}
+
+		fmt.Fprintf(&b, "    \"%s\" [%s];\n", dotEscape(node.Id), strings.Join(attrs, ", "))
+	}
+
+	// 顺序流
+	for _, edge := range structure.Edges {
+		if text := edgeText(edge); text != "" {
+			fmt.Fprintf(&b, "    \"%s\" -> \"%s\" [label=\"%s\"];\n", dotEscape(edge.Source), dotEscape(edge.Target), dotEscape(text))
+		} else {
+			fmt.Fprintf(&b, "    \"%s\" -> \"%s\";\n", dotEscape(edge.Source), dotEscape(edge.Target))
+		}
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+func dotEscape(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+
+	return s
+}
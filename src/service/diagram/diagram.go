@@ -0,0 +1,118 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/10 14:20
+ * @Desc: 流程图绘制的公共方法
+ */
+package diagram
+
+import (
+	"fmt"
+	"math"
+
+	"workflow/src/global/constant"
+	"workflow/src/model/dto"
+)
+
+// 流程图上需要额外标记的信息(当前节点、已完成节点、已走过的顺序流等)
+type Overlay struct {
+	CurrentNodeIds   map[string]bool     // 当前节点
+	CompletedNodeIds map[string]bool     // 已处理的节点
+	TakenEdgeIds     map[string]bool     // 已经走过的顺序流
+	NodeTitles       map[string][]string // 鼠标悬停显示的信息(处理人 处理时间等)
+}
+
+func NewOverlay() *Overlay {
+	return &Overlay{
+		CurrentNodeIds:   map[string]bool{},
+		CompletedNodeIds: map[string]bool{},
+		TakenEdgeIds:     map[string]bool{},
+		NodeTitles:       map[string][]string{},
+	}
+}
+
+type point struct {
+	X float64
+	Y float64
+}
+
+// 节点的宽高, 设计器没有传size的时候按照节点类型给默认值
+func nodeSize(node dto.Node) (width float64, height float64) {
+	if len(node.Size) >= 2 && node.Size[0] > 0 && node.Size[1] > 0 {
+		return float64(node.Size[0]), float64(node.Size[1])
+	}
+
+	switch node.Clazz {
+	case constant.START, constant.End:
+		return 30, 30
+	case constant.ExclusiveGateway, constant.ParallelGateway, constant.InclusiveGateway:
+		return 40, 40
+	default:
+		return 80, 44
+	}
+}
+
+// 获取节点的锚点坐标, 与设计器一致: 0上 1右 2下 3左
+// 锚点不合法的时候返回节点中心
+func anchorPoint(node dto.Node, anchor int64) point {
+	width, height := nodeSize(node)
+	anchors := []point{{0.5, 0}, {1, 0.5}, {0.5, 1}, {0, 0.5}}
+	if anchor < 0 || int(anchor) >= len(anchors) {
+		return point{node.X, node.Y}
+	}
+
+	a := anchors[anchor]
+	return point{
+		X: node.X + (a.X-0.5)*width,
+		Y: node.Y + (a.Y-0.5)*height,
+	}
+}
+
+// 计算整个流程图的边界
+func bounds(structure dto.Structure) (minX, minY, maxX, maxY float64) {
+	if len(structure.Nodes) == 0 {
+		return 0, 0, 0, 0
+	}
+
+	minX, minY = math.MaxFloat64, math.MaxFloat64
+	maxX, maxY = -math.MaxFloat64, -math.MaxFloat64
+	for _, node := range structure.Nodes {
+		width, height := nodeSize(node)
+		minX = math.Min(minX, node.X-width/2)
+		minY = math.Min(minY, node.Y-height/2)
+		maxX = math.Max(maxX, node.X+width/2)
+		maxY = math.Max(maxY, node.Y+height/2)
+	}
+
+	return
+}
+
+func isGateway(node dto.Node) bool {
+	return node.Clazz == constant.ExclusiveGateway ||
+		node.Clazz == constant.ParallelGateway ||
+		node.Clazz == constant.InclusiveGateway
+}
+
+func nodeMap(structure dto.Structure) map[string]dto.Node {
+	nodes := make(map[string]dto.Node, len(structure.Nodes))
+	for _, node := range structure.Nodes {
+		nodes[node.Id] = node
+	}
+
+	return nodes
+}
+
+// 顺序流上显示的文本, 有条件表达式的把表达式也带上, 默认流向显示为[默认]
+func edgeText(edge dto.Edge) string {
+	switch {
+	case edge.IsDefault && edge.Label == "":
+		return "[默认]"
+	case edge.IsDefault:
+		return fmt.Sprintf("%s [默认]", edge.Label)
+	case edge.ConditionExpression == "":
+		return edge.Label
+	case edge.Label == "":
+		return fmt.Sprintf("[%s]", edge.ConditionExpression)
+	default:
+		return fmt.Sprintf("%s [%s]", edge.Label, edge.ConditionExpression)
+	}
+}
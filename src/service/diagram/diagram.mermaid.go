@@ -0,0 +1,109 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/11 10:32
+ * @Desc: 流程模板导出为mermaid flowchart
+ */
+package diagram
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"workflow/src/global/constant"
+	"workflow/src/model/dto"
+)
+
+var mermaidIdRegexp = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// 导出mermaid的flowchart
+func RenderMermaid(structure dto.Structure) string {
+	ids := mermaidIds(structure)
+
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+
+	// 节点
+	var gateways, counterSigns, hiddenNodes []string
+	for _, node := range structure.Nodes {
+		id := ids[node.Id]
+		label := mermaidEscape(node.Label)
+		if label == "" {
+			label = mermaidEscape(node.Id)
+		}
+
+		switch {
+		case node.Clazz == constant.START || node.Clazz == constant.End:
+			fmt.Fprintf(&b, "    %s((\"%s\"))\n", id, label)
+		case isGateway(node):
+			fmt.Fprintf(&b, "    %s{\"%s\"}\n", id, label)
+			gateways = append(gateways, id)
+		case node.IsCounterSign:
+			fmt.Fprintf(&b, "    %s[[\"%s\"]]\n", id, label)
+			counterSigns = append(counterSigns, id)
+		default:
+			fmt.Fprintf(&b, "    %s[\"%s\"]\n", id, label)
+		}
+
+		if node.IsHideNode {
+			hiddenNodes = append(hiddenNodes, id)
+		}
+	}
+
+	// 顺序流
+	for _, edge := range structure.Edges {
+		source, sourceExist := ids[edge.Source]
+		target, targetExist := ids[edge.Target]
+		if !sourceExist || !targetExist {
+			continue
+		}
+
+		if text := edgeText(edge); text != "" {
+			fmt.Fprintf(&b, "    %s -->|\"%s\"| %s\n", source, mermaidEscape(text), target)
+		} else {
+			fmt.Fprintf(&b, "    %s --> %s\n", source, target)
+		}
+	}
+
+	// 样式
+	b.WriteString("    classDef gateway fill:#fffbe6,stroke:#faad14\n")
+	b.WriteString("    classDef counterSign fill:#e6f7ff,stroke:#1890ff,stroke-width:2px\n")
+	b.WriteString("    classDef hidden stroke-dasharray:4 2,color:#8c8c8c\n")
+	writeMermaidClass(&b, gateways, "gateway")
+	writeMermaidClass(&b, counterSigns, "counterSign")
+	writeMermaidClass(&b, hiddenNodes, "hidden")
+
+	return b.String()
+}
+
+func writeMermaidClass(b *strings.Builder, ids []string, class string) {
+	if len(ids) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "    class %s %s\n", strings.Join(ids, ","), class)
+}
+
+// mermaid的节点id只能包含字母数字下划线, 且不能是end等关键字
+// 所以统一加上前缀, 转换后重复的加上序号
+func mermaidIds(structure dto.Structure) map[string]string {
+	ids := make(map[string]string, len(structure.Nodes))
+	used := make(map[string]bool, len(structure.Nodes))
+	for index, node := range structure.Nodes {
+		id := "n_" + mermaidIdRegexp.ReplaceAllString(node.Id, "_")
+		if used[id] {
+			id = fmt.Sprintf("%s_%d", id, index)
+		}
+		used[id] = true
+		ids[node.Id] = id
+	}
+
+	return ids
+}
+
+func mermaidEscape(s string) string {
+	s = strings.Replace(s, `"`, "#quot;", -1)
+	s = strings.Replace(s, "\n", " ", -1)
+
+	return s
+}
@@ -0,0 +1,165 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/10 15:02
+ * @Desc: 服务端渲染svg格式的流程图
+ */
+package diagram
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+
+	"workflow/src/global/constant"
+	"workflow/src/model/dto"
+)
+
+const (
+	svgPadding = 20.0
+
+	colorDefaultStroke   = "#bfbfbf"
+	colorDefaultFill     = "#ffffff"
+	colorCurrentStroke   = "#1890ff"
+	colorCurrentFill     = "#e6f7ff"
+	colorCompletedStroke = "#52c41a"
+	colorCompletedFill   = "#f6ffed"
+	colorText            = "#262626"
+)
+
+// 渲染svg流程图, overlay为空的时候只渲染模板本身
+func RenderSVG(structure dto.Structure, overlay *Overlay) []byte {
+	if overlay == nil {
+		overlay = NewOverlay()
+	}
+
+	minX, minY, maxX, maxY := bounds(structure)
+	width := maxX - minX + svgPadding*2
+	height := maxY - minY + svgPadding*2
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="%.2f %.2f %.2f %.2f" font-family="sans-serif" font-size="12">`,
+		width, height, minX-svgPadding, minY-svgPadding, width, height)
+	buf.WriteString(`<defs>`)
+	writeArrowMarker(&buf, "arrow", colorDefaultStroke)
+	writeArrowMarker(&buf, "arrow-taken", colorCompletedStroke)
+	buf.WriteString(`</defs>`)
+
+	// 先画线再画节点, 避免线压在节点上面
+	nodes := nodeMap(structure)
+	for _, edge := range structure.Edges {
+		source, sourceExist := nodes[edge.Source]
+		target, targetExist := nodes[edge.Target]
+		if !sourceExist || !targetExist {
+			continue
+		}
+		writeEdge(&buf, edge, source, target, overlay.TakenEdgeIds[edge.Id])
+	}
+
+	for _, node := range structure.Nodes {
+		writeNode(&buf, node, overlay)
+	}
+
+	buf.WriteString(`</svg>`)
+
+	return buf.Bytes()
+}
+
+func writeArrowMarker(buf *bytes.Buffer, id string, color string) {
+	fmt.Fprintf(buf, `<marker id="%s" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="%s"/></marker>`, id, color)
+}
+
+func writeEdge(buf *bytes.Buffer, edge dto.Edge, source dto.Node, target dto.Node, taken bool) {
+	start := anchorPoint(source, edge.SourceAnchor)
+	end := anchorPoint(target, edge.TargetAnchor)
+
+	stroke, marker := colorDefaultStroke, "arrow"
+	if taken {
+		stroke, marker = colorCompletedStroke, "arrow-taken"
+	}
+
+	buf.WriteString(`<g>`)
+	if edge.ConditionExpression != "" {
+		fmt.Fprintf(buf, `<title>%s</title>`, escape(edge.ConditionExpression))
+	}
+	fmt.Fprintf(buf, `<line x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f" stroke="%s" stroke-width="%s" marker-end="url(#%s)"/>`,
+		start.X, start.Y, end.X, end.Y, stroke, strokeWidth(taken), marker)
+	if edge.Label != "" {
+		fmt.Fprintf(buf, `<text x="%.2f" y="%.2f" text-anchor="middle" fill="%s">%s</text>`,
+			(start.X+end.X)/2, (start.Y+end.Y)/2-4, colorText, escape(edge.Label))
+	}
+	buf.WriteString(`</g>`)
+}
+
+func writeNode(buf *bytes.Buffer, node dto.Node, overlay *Overlay) {
+	width, height := nodeSize(node)
+
+	// 当前节点的优先级高于已处理(比如会签节点已经有人处理过了)
+	stroke, fill, highlighted := colorDefaultStroke, colorDefaultFill, false
+	switch {
+	case overlay.CurrentNodeIds[node.Id]:
+		stroke, fill, highlighted = colorCurrentStroke, colorCurrentFill, true
+	case overlay.CompletedNodeIds[node.Id]:
+		stroke, fill, highlighted = colorCompletedStroke, colorCompletedFill, true
+	}
+
+	fmt.Fprintf(buf, `<g id="%s">`, escape(node.Id))
+	titles := append([]string{node.Label}, overlay.NodeTitles[node.Id]...)
+	fmt.Fprintf(buf, `<title>%s</title>`, escape(strings.Join(titles, "\n")))
+
+	switch {
+	case node.Clazz == constant.START || node.Clazz == constant.End:
+		fmt.Fprintf(buf, `<circle cx="%.2f" cy="%.2f" r="%.2f" fill="%s" stroke="%s" stroke-width="%s"/>`,
+			node.X, node.Y, width/2, fill, stroke, strokeWidth(highlighted || node.Clazz == constant.End))
+
+	case isGateway(node):
+		fmt.Fprintf(buf, `<polygon points="%.2f,%.2f %.2f,%.2f %.2f,%.2f %.2f,%.2f" fill="%s" stroke="%s" stroke-width="%s"/>`,
+			node.X, node.Y-height/2, node.X+width/2, node.Y, node.X, node.Y+height/2, node.X-width/2, node.Y,
+			fill, stroke, strokeWidth(highlighted))
+		fmt.Fprintf(buf, `<text x="%.2f" y="%.2f" text-anchor="middle" dominant-baseline="central" font-size="16" fill="%s">%s</text>`,
+			node.X, node.Y, stroke, gatewaySymbol(node.Clazz))
+
+	default:
+		dash := ""
+		if node.IsHideNode {
+			dash = ` stroke-dasharray="4 2"`
+		}
+		fmt.Fprintf(buf, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" rx="4" ry="4" fill="%s" stroke="%s" stroke-width="%s"%s/>`,
+			node.X-width/2, node.Y-height/2, width, height, fill, stroke, strokeWidth(highlighted), dash)
+	}
+
+	// 开始结束和网关的名称显示在节点下方, 其他的显示在节点中间
+	if node.Label != "" {
+		labelY := node.Y
+		if node.Clazz == constant.START || node.Clazz == constant.End || isGateway(node) {
+			labelY = node.Y + height/2 + 14
+		}
+		fmt.Fprintf(buf, `<text x="%.2f" y="%.2f" text-anchor="middle" dominant-baseline="central" fill="%s">%s</text>`,
+			node.X, labelY, colorText, escape(node.Label))
+	}
+
+	buf.WriteString(`</g>`)
+}
+
+func gatewaySymbol(clazz string) string {
+	switch clazz {
+	case constant.ParallelGateway:
+		return "+"
+	case constant.InclusiveGateway:
+		return "○"
+	default:
+		return "×"
+	}
+}
+
+func strokeWidth(bold bool) string {
+	if bold {
+		return "2"
+	}
+
+	return "1"
+}
+
+func escape(s string) string {
+	return html.EscapeString(s)
+}
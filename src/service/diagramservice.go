@@ -0,0 +1,127 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/10 16:11
+ * @Desc: 流程图
+ */
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"workflow/src/global"
+	"workflow/src/model"
+	"workflow/src/model/request"
+	"workflow/src/service/diagram"
+	"workflow/src/util"
+)
+
+// 获取流程实例的svg流程图
+// 当前节点高亮, 已处理的节点和走过的顺序流根据流转历史着色
+func GetProcessInstanceDiagram(instanceId int, c echo.Context) ([]byte, error) {
+	tenantId, _ := util.GetWorkContext(c)
+
+	// 复用获取实例的逻辑, 包括权限判断
+	resp, err := GetProcessInstance(&request.GetInstanceRequest{Id: instanceId}, c)
+	if err != nil {
+		return nil, err
+	}
+	instance := resp.ProcessInstance
+
+	definition, err := GetDefinition(instance.ProcessDefinitionId, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	var histories []model.CirculationHistory
+	err = global.BankDb.
+		Where("process_instance_id = ?", instance.Id).
+		Order("id").
+		Find(&histories).
+		Error
+	if err != nil {
+		global.BankLogger.Error(err)
+		return nil, util.NewError("查询流转历史失败")
+	}
+
+	userNames := getUserNames(collectHistoryProcessors(histories, instance), tenantId)
+	overlay := diagram.NewOverlay()
+
+	// 根据流转历史标记已处理的节点和走过的线
+	for _, history := range histories {
+		overlay.CompletedNodeIds[history.SourceId] = true
+		if history.TargetId != "" {
+			for _, edge := range definition.Structure.Edges {
+				if edge.Source == history.SourceId && edge.Target == history.TargetId {
+					overlay.TakenEdgeIds[edge.Id] = true
+				}
+			}
+		}
+
+		title := fmt.Sprintf("%s %s %s", displayUserName(history.ProcessorId, userNames), history.CreateTime.Format("2006-01-02 15:04"), history.Circulation)
+		overlay.NodeTitles[history.SourceId] = append(overlay.NodeTitles[history.SourceId], strings.TrimSpace(title))
+	}
+
+	// 标记当前节点以及待处理人
+	for _, state := range instance.State {
+		overlay.CurrentNodeIds[state.Id] = true
+		if len(state.UnCompletedProcessor) == 0 {
+			continue
+		}
+
+		names := make([]string, 0, len(state.UnCompletedProcessor))
+		for _, processor := range state.UnCompletedProcessor {
+			names = append(names, displayUserName(processor, userNames))
+		}
+		overlay.NodeTitles[state.Id] = append(overlay.NodeTitles[state.Id], "待处理: "+strings.Join(names, ", "))
+	}
+
+	return diagram.RenderSVG(definition.Structure, overlay), nil
+}
+
+func collectHistoryProcessors(histories []model.CirculationHistory, instance model.ProcessInstance) []string {
+	identifiers := make([]string, 0, len(histories))
+	for _, history := range histories {
+		identifiers = append(identifiers, history.ProcessorId)
+	}
+	for _, state := range instance.State {
+		identifiers = append(identifiers, state.UnCompletedProcessor...)
+	}
+
+	return identifiers
+}
+
+// 获取外部系统同步过来的用户名称, key为用户的identifier
+func getUserNames(identifiers []string, tenantId int) map[string]string {
+	names := make(map[string]string, len(identifiers))
+	if len(identifiers) == 0 {
+		return names
+	}
+
+	var users []model.User
+	err := global.BankDb.
+		Where("tenant_id = ?", tenantId).
+		Where("identifier in ?", identifiers).
+		Find(&users).
+		Error
+	if err != nil {
+		global.BankLogger.Error("查询用户名称失败", err)
+		return names
+	}
+
+	for _, user := range users {
+		names[user.Identifier] = user.Name
+	}
+
+	return names
+}
+
+func displayUserName(identifier string, names map[string]string) string {
+	if name, ok := names[identifier]; ok && name != "" {
+		return fmt.Sprintf("%s(%s)", name, identifier)
+	}
+
+	return identifier
+}
@@ -0,0 +1,117 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/20 15:30
+ * @Desc: 当前用户可以进行的操作
+ */
+package engine
+
+import (
+	"workflow/src/global/constant"
+	"workflow/src/model/dto"
+	"workflow/src/model/response"
+	"workflow/src/util"
+)
+
+// 获取当前用户在各个待处理节点上可以进行的操作
+func (engine *ProcessEngine) GetNodeActions() []response.NodeActions {
+	nodes := make([]response.NodeActions, 0)
+	if engine.ProcessInstance.IsEnd || engine.ProcessInstance.IsDenied {
+		return nodes
+	}
+
+	provided := make(map[string]bool)
+	for _, variable := range util.UnmarshalToInstanceVariables(engine.ProcessInstance.Variables) {
+		provided[variable.Name] = true
+	}
+
+	for _, state := range engine.ProcessInstance.State {
+		if !util.SliceAnyString(state.UnCompletedProcessor, engine.userIdentifier) {
+			continue
+		}
+
+		edges := engine.GetEdges(state.Id, "source")
+		approveCount := 0
+		conditionRouted := false
+		for _, edge := range edges {
+			if edge.FlowProperties == "1" {
+				approveCount++
+				conditionRouted = conditionRouted || isConditionalEdge(edge)
+			}
+		}
+
+		edgeActions := make([]response.EdgeAction, 0, len(edges))
+		for _, edge := range edges {
+			targetNode, _ := engine.GetNode(edge.Target)
+
+			requiredVariables := make([]response.RequiredVariable, 0)
+			for _, name := range engine.getRequiredVariables(edge, make(map[string]bool)) {
+				requiredVariables = append(requiredVariables, response.RequiredVariable{
+					Name:       name,
+					IsProvided: provided[name],
+				})
+			}
+
+			edgeActions = append(edgeActions, response.EdgeAction{
+				EdgeId:            edge.Id,
+				Label:             edge.Label,
+				Action:            edgeAction(edge),
+				IsDefaultApprove:  edge.FlowProperties == "1" && approveCount == 1 && !conditionRouted,
+				TargetNodeId:      targetNode.Id,
+				TargetNodeLabel:   targetNode.Label,
+				RequiredVariables: requiredVariables,
+			})
+		}
+
+		nodes = append(nodes, response.NodeActions{
+			NodeId:          state.Id,
+			NodeLabel:       state.Label,
+			IsCounterSign:   state.IsCounterSign,
+			CanDeny:         true,
+			ConditionRouted: conditionRouted,
+			Edges:           edgeActions,
+		})
+	}
+
+	return nodes
+}
+
+// 走某条顺序流需要的变量: 顺序流本身和后续连续的网关上的条件表达式引用的变量
+// 表达式解析失败的忽略, 处理的时候会返回具体的错误
+func (engine *ProcessEngine) getRequiredVariables(edge dto.Edge, visited map[string]bool) []string {
+	if visited[edge.Id] {
+		return nil
+	}
+	visited[edge.Id] = true
+
+	variables := make([]string, 0)
+	if edge.ConditionExpression != "" {
+		names, err := util.ExpressionVariables(normalizeExpression(edge.ConditionExpression))
+		if err == nil {
+			variables = append(variables, names...)
+		}
+	}
+
+	targetNode, err := engine.GetNode(edge.Target)
+	if err != nil {
+		return variables
+	}
+	switch targetNode.Clazz {
+	case constant.ExclusiveGateway, constant.ParallelGateway, constant.InclusiveGateway:
+		for _, nextEdge := range engine.GetEdges(targetNode.Id, "source") {
+			variables = append(variables, engine.getRequiredVariables(nextEdge, visited)...)
+		}
+	}
+
+	return distinctStrings(variables)
+}
+
+func edgeAction(edge dto.Edge) string {
+	switch edge.FlowProperties {
+	case "1":
+		return response.EdgeActionApprove
+	case "0":
+		return response.EdgeActionReject
+	default:
+		return response.EdgeActionOther
+	}
+}
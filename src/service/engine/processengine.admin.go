@@ -0,0 +1,189 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/19 10:30
+ * @Desc: 管理员的特权操作
+ */
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"workflow/src/global/constant"
+	"workflow/src/model/dto"
+	"workflow/src/util"
+)
+
+// 管理员操作在流转历史中的说明
+const (
+	AdminJump      = "管理员跳转"
+	AdminReassign  = "管理员转派"
+	AdminTerminate = "管理员终止"
+	AdminDelete    = "管理员删除"
+	AdminUndelete  = "管理员恢复"
+)
+
+// 强制跳转到指定节点, 当前所有节点的待办都会被取消
+// 目标节点只能是用户任务或者结束节点, 被否决的流程跳转之后会重新进入进行中
+func (engine *ProcessEngine) Jump(nodeId string, remark string) error {
+	targetNode, err := engine.GetNode(nodeId)
+	if err != nil {
+		return util.BadRequest.New(err)
+	}
+	if targetNode.Clazz != constant.UserTask && targetNode.Clazz != constant.End {
+		return util.BadRequest.Newf("只能跳转到用户任务或者结束节点, 当前节点类型为: %s", targetNode.Clazz)
+	}
+
+	newStates, err := engine.GenNewStates([]dto.Node{targetNode})
+	if err != nil {
+		return err
+	}
+
+	sourceId, sourceState := engine.currentStatesSummary()
+	err = engine.updateInstanceByAdmin(map[string]interface{}{
+		"state":     newStates,
+		"is_end":    targetNode.Clazz == constant.End,
+		"is_denied": false,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = engine.SyncTasks(newStates)
+	if err != nil {
+		return err
+	}
+	engine.ProcessInstance.State = newStates
+
+	return engine.CreateAdminHistory(AdminJump, sourceId, sourceState, targetNode.Id, remark)
+}
+
+// 修改当前节点的处理人
+// fromUser为空的时候替换该节点所有未处理的人, 否则只替换fromUser
+func (engine *ProcessEngine) Reassign(nodeId string, fromUser string, toUsers []string, remark string) error {
+	if len(toUsers) == 0 {
+		return util.BadRequest.New("新的处理人不能为空")
+	}
+
+	newStates := make(dto.StateArray, len(engine.ProcessInstance.State))
+	copy(newStates, engine.ProcessInstance.State)
+
+	index := -1
+	for i, state := range newStates {
+		if state.Id == nodeId {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return util.BadRequest.Newf("当前流程不在节点%s上", nodeId)
+	}
+
+	state := newStates[index]
+	unCompleted := make([]string, 0, len(state.UnCompletedProcessor)+len(toUsers))
+	if fromUser == "" {
+		unCompleted = append(unCompleted, toUsers...)
+	} else {
+		if !util.SliceAnyString(state.UnCompletedProcessor, fromUser) {
+			return util.BadRequest.Newf("%s不是当前节点未处理的人", fromUser)
+		}
+		for _, processor := range state.UnCompletedProcessor {
+			if processor == fromUser {
+				unCompleted = append(unCompleted, toUsers...)
+			} else {
+				unCompleted = append(unCompleted, processor)
+			}
+		}
+	}
+	unCompleted = distinctStrings(util.SliceDiff(unCompleted, state.CompletedProcessor))
+
+	// 完整的处理人列表 = 已处理的人 + 未处理的人
+	processors := make([]string, 0, len(state.CompletedProcessor)+len(unCompleted))
+	processors = append(processors, state.CompletedProcessor...)
+	processors = append(processors, unCompleted...)
+
+	state.UnCompletedProcessor = unCompleted
+	state.Processor = processors
+	newStates[index] = state
+
+	err := engine.updateInstanceByAdmin(map[string]interface{}{
+		"state": newStates,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = engine.SyncTasks(newStates)
+	if err != nil {
+		return err
+	}
+	engine.ProcessInstance.State = newStates
+
+	from := fromUser
+	if from == "" {
+		from = "所有未处理的人"
+	}
+	if remark == "" {
+		remark = fmt.Sprintf("%s 转派给 %s", from, strings.Join(toUsers, ","))
+	}
+
+	return engine.CreateAdminHistory(AdminReassign, state.Id, state.Label, state.Id, remark)
+}
+
+// 终止流程, 当前所有的待办都会被取消
+func (engine *ProcessEngine) Terminate(remark string) error {
+	if engine.ProcessInstance.IsEnd || engine.ProcessInstance.IsDenied {
+		return util.BadRequest.New("当前流程已经结束")
+	}
+
+	sourceId, sourceState := engine.currentStatesSummary()
+	err := engine.updateInstanceByAdmin(map[string]interface{}{
+		"state":  dto.StateArray{},
+		"is_end": true,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = engine.SyncTasks(dto.StateArray{})
+	if err != nil {
+		return err
+	}
+	engine.ProcessInstance.State = dto.StateArray{}
+
+	return engine.CreateAdminHistory(AdminTerminate, sourceId, sourceState, "", remark)
+}
+
+func (engine *ProcessEngine) updateInstanceByAdmin(toUpdate map[string]interface{}) error {
+	toUpdate["update_time"] = time.Now().Local()
+	toUpdate["update_by"] = engine.userIdentifier
+
+	return engine.UpdateInstance(toUpdate)
+}
+
+// 当前所在节点的id和名称, 多个节点(并行)的用逗号分隔
+func (engine *ProcessEngine) currentStatesSummary() (string, string) {
+	ids := make([]string, 0, len(engine.ProcessInstance.State))
+	labels := make([]string, 0, len(engine.ProcessInstance.State))
+	for _, state := range engine.ProcessInstance.State {
+		ids = append(ids, state.Id)
+		labels = append(labels, state.Label)
+	}
+
+	return strings.Join(ids, ","), strings.Join(labels, ",")
+}
+
+func distinctStrings(items []string) []string {
+	result := make([]string, 0, len(items))
+	exist := make(map[string]bool, len(items))
+	for _, item := range items {
+		if item == "" || exist[item] {
+			continue
+		}
+		exist[item] = true
+		result = append(result, item)
+	}
+
+	return result
+}
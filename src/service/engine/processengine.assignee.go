@@ -0,0 +1,122 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/26 21:12
+ * @Desc: 根据流程变量确定处理人的相关方法
+ */
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"workflow/src/global"
+	"workflow/src/model"
+	"workflow/src/model/dto"
+	"workflow/src/util"
+)
+
+// 通过流程变量获取处理人
+// assignValue中的每一项是变量名或者表达式, 结果可以是用户标识或者用户标识的列表
+// 结果为空(包括变量不存在)的时候使用assignBackup, 没有配置assignBackup的返回错误
+func (engine *ProcessEngine) GetUserIdsByVariables(node dto.Node) ([]string, error) {
+	variables := engine.expressionVariables()
+	ctx := engine.expressionContext()
+
+	processors := make([]string, 0, 1)
+	for _, expression := range node.AssignValue {
+		expression = normalizeExpression(expression)
+		output, err := util.EvaluateExpression(expression, variables, ctx)
+		if err != nil {
+			var exprErr *util.ExpressionError
+			if errors.As(err, &exprErr) && len(exprErr.MissingVariables) > 0 {
+				continue
+			}
+			return nil, util.BadRequest.Newf("节点[%s]的处理人计算失败: %s", nodeDisplayName(node), err.Error())
+		}
+
+		userIds, err := toUserIdentifiers(output)
+		if err != nil {
+			return nil, util.BadRequest.Newf("节点[%s]的处理人表达式[%s]%s", nodeDisplayName(node), expression, err.Error())
+		}
+		processors = append(processors, userIds...)
+	}
+	processors = distinctStrings(processors)
+
+	if len(processors) == 0 {
+		if len(node.AssignBackup) > 0 {
+			return node.AssignBackup, nil
+		}
+		return nil, util.BadRequest.Newf("节点[%s]的处理人变量[%s]为空, 并且没有配置备用处理人", nodeDisplayName(node), strings.Join(node.AssignValue, ", "))
+	}
+
+	// 变量中的用户必须存在
+	var existUserIds []string
+	err := global.BankDb.Model(&model.User{}).
+		Where("tenant_id = ?", engine.tenantId).
+		Where("identifier in ?", processors).
+		Pluck("identifier", &existUserIds).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	missingUserIds := make([]string, 0)
+	for _, userId := range processors {
+		if !util.SliceAnyString(existUserIds, userId) {
+			missingUserIds = append(missingUserIds, userId)
+		}
+	}
+	if len(missingUserIds) > 0 {
+		return nil, util.BadRequest.Newf("节点[%s]的处理人变量中的用户不存在: %s", nodeDisplayName(node), strings.Join(missingUserIds, ", "))
+	}
+
+	return processors, nil
+}
+
+// 把表达式的结果转换成用户标识, 支持字符串、数字以及它们的列表, 空值会被忽略
+func toUserIdentifiers(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		userIds := make([]string, 0, len(v))
+		for _, item := range v {
+			if item = strings.TrimSpace(item); item != "" {
+				userIds = append(userIds, item)
+			}
+		}
+		return userIds, nil
+	case []interface{}:
+		userIds := make([]string, 0, len(v))
+		for _, item := range v {
+			itemUserIds, err := toUserIdentifiers(item)
+			if err != nil {
+				return nil, err
+			}
+			userIds = append(userIds, itemUserIds...)
+		}
+		return userIds, nil
+	}
+
+	var userId string
+	switch v := value.(type) {
+	case string:
+		userId = strings.TrimSpace(v)
+	case int:
+		userId = strconv.Itoa(v)
+	case int64:
+		userId = strconv.FormatInt(v, 10)
+	case float64:
+		userId = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil, fmt.Errorf("的结果不是用户标识, 当前结果为: %v(%T)", value, value)
+	}
+
+	if userId == "" {
+		return nil, nil
+	}
+
+	return []string{userId}, nil
+}
@@ -30,12 +30,13 @@ func (engine *ProcessEngine) Circulation(newStates dto.StateArray) error {
 		toUpdate["is_end"] = true
 	}
 
-	err := engine.tx.
-		Model(&engine.ProcessInstance).
-		Updates(toUpdate).
-		Error
+	err := engine.UpdateInstance(toUpdate)
+	if err != nil {
+		return err
+	}
 
-	return err
+	// 同步待办任务
+	return engine.SyncTasks(newStates)
 }
 
 // 否决
@@ -52,10 +53,17 @@ func (engine *ProcessEngine) Deny(r *request.DenyInstanceRequest) error {
 		"state":          dto.StateArray{},
 	}
 
-	err := engine.tx.
-		Model(&engine.ProcessInstance).
-		Updates(toUpdate).
-		Error
+	err := engine.UpdateInstance(toUpdate)
+	if err != nil {
+		return err
+	}
+
+	// 同步待办任务, 否决之后所有的待办都会被取消
+	engine.SetTaskOutcome(r.NodeId, "否决")
+	err = engine.SyncTasks(dto.StateArray{})
+	if err != nil {
+		return err
+	}
 
 	// 获取当前的node
 	node, err := engine.GetNode(r.NodeId)
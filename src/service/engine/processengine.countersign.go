@@ -88,15 +88,17 @@ func (engine *ProcessEngine) UpdateInstanceForCounterSign() error {
 		"variables":      engine.ProcessInstance.Variables,
 	}
 
-	err := engine.tx.Model(&engine.ProcessInstance).
-		Updates(toUpdate).
-		Error
+	err := engine.UpdateInstance(toUpdate)
+	if err != nil {
+		return err
+	}
 
-	return err
+	// 同步待办任务
+	return engine.SyncTasks(engine.ProcessInstance.State)
 }
 
 func (engine *ProcessEngine) RemoveCurrentFromUnCompleted(unCompletedProcessors []string) []string {
-	newArr := make([]string, len(unCompletedProcessors)-1)
+	newArr := make([]string, 0, len(unCompletedProcessors))
 	for _, it := range unCompletedProcessors {
 		if it == engine.userIdentifier {
 			continue
@@ -0,0 +1,60 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/14 11:20
+ * @Desc: 流程事件的相关方法
+ */
+package engine
+
+import (
+	"time"
+
+	"workflow/src/model"
+	"workflow/src/service/event"
+)
+
+// 发布本次处理产生的事件, 必须在事务提交成功之后调用
+// 除了待办的变化以外, 流程实例的相关人和当前处理人都会收到实例更新的事件
+func (engine *ProcessEngine) PublishEvents() {
+	receivers := make(map[string]bool)
+	for _, person := range engine.ProcessInstance.RelatedPerson {
+		receivers[person] = true
+	}
+	for _, state := range engine.ProcessInstance.State {
+		for _, processor := range state.Processor {
+			receivers[processor] = true
+		}
+	}
+	for _, message := range engine.events {
+		receivers[message.UserIdentifier] = true
+	}
+
+	now := time.Now().Local()
+	for receiver := range receivers {
+		if receiver == "" {
+			continue
+		}
+		engine.events = append(engine.events, event.Message{
+			Type:              event.InstanceUpdated,
+			TenantId:          engine.tenantId,
+			UserIdentifier:    receiver,
+			ProcessInstanceId: engine.ProcessInstance.Id,
+			Title:             engine.ProcessInstance.Title,
+			CreateTime:        now,
+		})
+	}
+
+	event.Publish(engine.events...)
+	engine.events = nil
+}
+
+func (engine *ProcessEngine) addTaskEvent(eventType string, task model.Task) {
+	engine.events = append(engine.events, event.Message{
+		Type:              eventType,
+		TenantId:          engine.tenantId,
+		UserIdentifier:    task.Assignee,
+		ProcessInstanceId: engine.ProcessInstance.Id,
+		Title:             engine.ProcessInstance.Title,
+		NodeId:            task.NodeId,
+		CreateTime:        time.Now().Local(),
+	})
+}
@@ -12,6 +12,8 @@ import (
 
 	"workflow/src/global"
 	"workflow/src/global/constant"
+	"workflow/src/global/shared"
+	"workflow/src/model"
 	"workflow/src/model/dto"
 	"workflow/src/model/request"
 	"workflow/src/util"
@@ -22,9 +24,15 @@ func (engine *ProcessEngine) ProcessingExclusiveGateway(gatewayNode dto.Node, r
 	// 1. 找到所有source为当前网关节点的edges, 并按照sort排序
 	edges := engine.GetEdges(gatewayNode.Id, "source")
 
-	// 2. 遍历edges, 获取当前第一个符合条件的edge
+	// 2. 遍历edges, 获取当前第一个符合条件的edge, 默认流向不参与条件判断
 	hitEdge := dto.Edge{}
+	defaultEdge := dto.Edge{}
 	for _, edge := range edges {
+		if edge.IsDefault {
+			defaultEdge = edge
+			continue
+		}
+
 		if edge.ConditionExpression == "" {
 			return errors.New("处理失败, 排他网关的后续流程的条件表达式不能为空, 请检查")
 		}
@@ -32,7 +40,7 @@ func (engine *ProcessEngine) ProcessingExclusiveGateway(gatewayNode dto.Node, r
 		// 进行条件判断
 		condExprStatus, err := engine.ConditionJudgment(edge.ConditionExpression)
 		if err != nil {
-			return err
+			return util.BadRequest.Newf("排他网关[%s]的顺序流[%s]的条件表达式计算失败: %s", nodeDisplayName(gatewayNode), edgeDisplayName(edge), err.Error())
 		}
 		// 获取成功的节点
 		if condExprStatus {
@@ -41,6 +49,11 @@ func (engine *ProcessEngine) ProcessingExclusiveGateway(gatewayNode dto.Node, r
 		}
 	}
 
+	// 都不满足的时候走默认流向
+	if hitEdge.Id == "" {
+		hitEdge = defaultEdge
+	}
+
 	if hitEdge.Id == "" {
 		return errors.New("没有符合条件的流向，请检查")
 	}
@@ -78,26 +91,85 @@ func (engine *ProcessEngine) ProcessingExclusiveGateway(gatewayNode dto.Node, r
 
 // 条件表达式判断
 func (engine *ProcessEngine) ConditionJudgment(condExpr string) (bool, error) {
-	// 先获取变量列表
+	envMap := engine.expressionVariables()
+	condExpr = normalizeExpression(condExpr)
+	result, err := util.CalculateExpression(condExpr, envMap, engine.expressionContext())
+	if err != nil {
+		global.BankLogger.Errorf("计算表达式发生错误, 当前表达式：%s ,当前变量:%v, 错误原因：%s", condExpr, envMap, err.Error())
+		return false, err
+	}
+
+	return result, nil
+}
+
+// 表达式中可以使用的流程实例变量
+func (engine *ProcessEngine) expressionVariables() map[string]interface{} {
 	variables := util.UnmarshalToInstanceVariables(engine.ProcessInstance.Variables)
 
 	envMap := make(map[string]interface{}, len(variables))
 	for _, variable := range variables {
-		envMap[variable.Name] = variable.Value
+		envMap[variable.Name] = util.ExpressionValue(variable)
 	}
 
-	// 替换变量表达式符
+	return envMap
+}
+
+// 替换变量表达式符
+func normalizeExpression(condExpr string) string {
 	condExpr = strings.Replace(condExpr, "{{", "", -1)
 	condExpr = strings.Replace(condExpr, "}}", "", -1)
 	condExpr = strings.Replace(condExpr, "&gt;", ">", -1)
 	condExpr = strings.Replace(condExpr, "&lt;", "<", -1)
 
-	result, err := util.CalculateExpression(condExpr, envMap)
-	if err != nil {
-		err = fmt.Errorf("计算表达式发生错误, 当前表达式：%s ,当前变量:%v, 错误原因：%s", condExpr, envMap, err.Error())
-		global.BankLogger.Error(err)
-		return false, err
+	return condExpr
+}
+
+// 错误信息中节点的显示名称, 没有label的时候使用id
+func nodeDisplayName(node dto.Node) string {
+	if node.Label == "" {
+		return node.Id
 	}
 
-	return result, nil
+	return node.Label
+}
+
+// 错误信息中顺序流的显示名称, 没有label的时候使用id
+func edgeDisplayName(edge dto.Edge) string {
+	if edge.Label == "" {
+		return edge.Id
+	}
+
+	return edge.Label
+}
+
+// 表达式中initiator()、userInRole()等函数使用的上下文
+func (engine *ProcessEngine) expressionContext() *util.ExpressionContext {
+	return &util.ExpressionContext{
+		Initiator:        engine.ProcessInstance.CreateBy,
+		CurrentProcessor: engine.userIdentifier,
+		CacheScope:       fmt.Sprintf("%d:%d", engine.ProcessDefinition.Id, engine.ProcessDefinition.Version),
+		UserRoles: func(userIdentifier string) []string {
+			roles, err := shared.GetUserRoleIdentifiers(userIdentifier, engine.tenantId)
+			if err != nil {
+				panic(err)
+			}
+			return roles
+		},
+		RoleUsers: func(roleIdentifier string) []string {
+			users, err := engine.GetUserIdsByRoleIds([]string{roleIdentifier})
+			if err != nil {
+				panic(err)
+			}
+			return users
+		},
+		UserName: func(userIdentifier string) string {
+			var user model.User
+			global.BankDb.
+				Where("identifier = ?", userIdentifier).
+				Where("tenant_id = ?", engine.tenantId).
+				Select("name").
+				First(&user)
+			return user.Name
+		},
+	}
 }
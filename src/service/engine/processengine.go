@@ -8,8 +8,8 @@ package engine
 import (
 	"errors"
 	"fmt"
+	"sort"
 
-	. "github.com/ahmetb/go-linq/v3"
 	"gorm.io/gorm"
 
 	"workflow/src/global"
@@ -17,6 +17,7 @@ import (
 	"workflow/src/model"
 	"workflow/src/model/dto"
 	"workflow/src/model/request"
+	"workflow/src/service/event"
 	"workflow/src/util"
 )
 
@@ -27,6 +28,11 @@ type ProcessEngine struct {
 	sourceNode          *dto.Node               // 流转的源node
 	targetNode          *dto.Node               // 流转的目标node
 	linkEdge            *dto.Edge               // sourceNode和targetNode中间连接的edge
+	taskNodeId          string                  // 当前用户处理的节点id(用于同步待办任务)
+	taskOutcome         string                  // 当前用户的处理结果(用于同步待办任务)
+	events              []event.Message         // 待发布的事件, 事务提交后发布
+	variableChanges     []model.VariableHistory // 待保存的变量变更历史
+	ccNodes             []dto.Node              // 新进入的需要抄送的节点, 同步待办任务时生成抄送
 	ProcessInstance     model.ProcessInstance   // 流程实例
 	ProcessDefinition   model.ProcessDefinition // 流程定义
 	DefinitionStructure dto.Structure           // ProcessDefinition.Structure的快捷方式
@@ -136,8 +142,15 @@ func (engine *ProcessEngine) Handle(r *request.HandleInstancesRequest) error {
 
 	// 设置当前的节点和顺序流信息
 	engine.SetCurrentNodeEdgeInfo(&sourceNode, &edge, &targetNode)
+	engine.SetTaskOutcome(sourceNode.Id, edgeOutcome(edge))
 	engine.UpdateRelatedPerson()
 
+	// 保存本次处理带来的变量变更
+	err = engine.SaveVariableChanges(sourceNode)
+	if err != nil {
+		return err
+	}
+
 	// handle内部(有递归操作，针对比如网关后还是网关等场景)
 	return engine.handleInternal(r, 1)
 }
@@ -231,6 +244,15 @@ func (engine *ProcessEngine) handleInternal(r *request.HandleInstancesRequest, d
 	return nil
 }
 
+// 顺序流对应的处理结果, 没有名称的用id
+func edgeOutcome(edge dto.Edge) string {
+	if edge.Label != "" {
+		return edge.Label
+	}
+
+	return edge.Id
+}
+
 func (engine *ProcessEngine) SetCurrentNodeEdgeInfo(sourceNode *dto.Node, edge *dto.Edge, targetNode *dto.Node) {
 	engine.sourceNode = sourceNode
 	engine.linkEdge = edge
@@ -269,9 +291,9 @@ func (engine *ProcessEngine) GetEdges(nodeId string, nodeIdType string) []dto.Ed
 		}
 	}
 
-	// 根据sort排序
-	From(edges).OrderByT(func(i dto.Edge) interface{} {
-		return i.Sort
+	// 根据sort排序, 条件路由按照这个顺序判断; sort相同的保持定义中的顺序
+	sort.SliceStable(edges, func(i, j int) bool {
+		return util.StringToInt(edges[i].Sort) < util.StringToInt(edges[j].Sort)
 	})
 
 	return edges
@@ -339,8 +361,8 @@ func (engine *ProcessEngine) GenNewStates(nodes []dto.Node) (dto.StateArray, err
 			IsCounterSign:      node.IsCounterSign,
 		}
 
-		// 审批者是role的需要在这里转成person
-		if node.AssignType != "" && node.AssignValue != nil {
+		// 审批者是role的需要在这里转成person, 组织架构类型的可以不配置assignValue
+		if node.AssignType != "" && (node.AssignValue != nil || isOrganizationAssignType(node.AssignType)) {
 			switch node.AssignType {
 			case "role": // 审批者是role, 需要转成person
 				processors, err := engine.GetUserIdsByRoleIds(node.AssignValue)
@@ -354,11 +376,32 @@ func (engine *ProcessEngine) GenNewStates(nodes []dto.Node) (dto.StateArray, err
 				state.Processor = node.AssignValue
 				state.UnCompletedProcessor = node.AssignValue
 				break
+			case "variable": // 审批者来自流程变量或者表达式, 在进入节点的时候计算
+				processors, err := engine.GetUserIdsByVariables(node)
+				if err != nil {
+					return nil, err
+				}
+				state.Processor = processors
+				state.UnCompletedProcessor = processors
+				break
+			case "initiatorManager", "initiatorNthManager", "departmentLeader": // 审批者根据组织架构计算
+				processors, err := engine.GetUserIdsByOrganization(node)
+				if err != nil {
+					return nil, err
+				}
+				state.Processor = processors
+				state.UnCompletedProcessor = processors
+				break
 			default:
 				return nil, fmt.Errorf("不支持的处理人类型: %s", node.AssignType)
 			}
 		}
 
+		// 配置了抄送的节点, 同步待办任务的时候生成抄送
+		if len(node.CcUsers) > 0 || len(node.CcRoles) > 0 {
+			engine.ccNodes = append(engine.ccNodes, node)
+		}
+
 		// 获取可用的edge
 		availableEdges := make([]dto.Edge, 0, 1)
 		for _, edge := range engine.DefinitionStructure.Edges {
@@ -412,8 +455,8 @@ func (engine *ProcessEngine) GetUserIdsByRoleIds(roleIds []string) ([]string, er
 	return userIds, err
 }
 
-// 合并更新变量
-func (engine *ProcessEngine) MergeVariables(newVariables []model.InstanceVariable) {
+// 合并更新变量, 没有指定类型的变量沿用原来的类型, 原来没有的根据值推断类型
+func (engine *ProcessEngine) MergeVariables(newVariables []model.InstanceVariable) error {
 	// 反序列化出来
 	originVariables := util.UnmarshalToInstanceVariables(engine.ProcessInstance.Variables)
 
@@ -424,6 +467,22 @@ func (engine *ProcessEngine) MergeVariables(newVariables []model.InstanceVariabl
 	}
 
 	for _, v := range newVariables {
+		origin, exist := originVariableMap[v.Name]
+		if v.Type == "" {
+			v.Type = origin.Type
+		}
+		if v.Type == "" {
+			v.Type = util.InferVariableType(v.Value)
+		}
+		if err := util.CoerceVariable(&v); err != nil {
+			return util.BadRequest.New(err)
+		}
+
+		if exist {
+			engine.addVariableChange(v.Name, util.MarshalToDbJson(origin.Value), v.Value)
+		} else {
+			engine.addVariableChange(v.Name, nil, v.Value)
+		}
 		originVariableMap[v.Name] = v
 	}
 
@@ -433,6 +492,8 @@ func (engine *ProcessEngine) MergeVariables(newVariables []model.InstanceVariabl
 	}
 
 	engine.ProcessInstance.Variables = util.MarshalToDbJson(finalVariables)
+
+	return nil
 }
 
 // 获取初始节点
@@ -501,3 +562,140 @@ func (engine *ProcessEngine) GetStateByEdgeId(edge dto.Edge) (dto.State, error)
 
 	return state, nil
 }
+
+// 获取当前用户待处理的节点, 当前用户同时在多个节点上待处理(并行)的时候需要明确指定节点
+func (engine *ProcessEngine) GetCurrentUserState() (dto.State, error) {
+	states := make([]dto.State, 0, 1)
+	for _, state := range engine.ProcessInstance.State {
+		if util.SliceAnyString(state.UnCompletedProcessor, engine.userIdentifier) {
+			states = append(states, state)
+		}
+	}
+
+	switch len(states) {
+	case 0:
+		return dto.State{}, util.Forbidden.New("当前用户没有需要处理的节点")
+	case 1:
+		return states[0], nil
+	default:
+		return dto.State{}, util.BadRequest.New("当前用户在多个节点上都需要处理, 请指定具体的节点")
+	}
+}
+
+// 确定审批实际要走的顺序流
+// 1. 指定了不带条件表达式的edgeId, 直接走该顺序流
+// 2. 指定了带条件表达式的edgeId, 在同一节点出发的同类型(flowProperties相同)的顺序流中根据条件选择
+// 3. 没有指定edgeId, 在当前用户待处理节点的同意的顺序流(flowProperties为"1")中选择
+// 需要在合并审批提交的变量之后调用
+func (engine *ProcessEngine) ResolveHandleEdge(edgeId string) (dto.Edge, error) {
+	var (
+		sourceId       string
+		flowProperties string
+	)
+	if edgeId != "" {
+		edge, err := engine.GetEdge(edgeId)
+		if err != nil {
+			return dto.Edge{}, util.BadRequest.New(err)
+		}
+		if !isConditionalEdge(edge) {
+			return edge, nil
+		}
+		sourceId, flowProperties = edge.Source, edge.FlowProperties
+	} else {
+		state, err := engine.GetCurrentUserState()
+		if err != nil {
+			return dto.Edge{}, err
+		}
+		sourceId, flowProperties = state.Id, "1"
+	}
+
+	sourceNode, err := engine.GetNode(sourceId)
+	if err != nil {
+		return dto.Edge{}, util.BadRequest.New(err)
+	}
+
+	edges := make([]dto.Edge, 0, 1)
+	conditional := false
+	for _, edge := range engine.GetEdges(sourceId, "source") {
+		if edge.FlowProperties == flowProperties {
+			edges = append(edges, edge)
+			conditional = conditional || isConditionalEdge(edge)
+		}
+	}
+
+	if conditional {
+		return engine.selectConditionalEdge(sourceNode, edges)
+	}
+
+	switch len(edges) {
+	case 0:
+		return dto.Edge{}, util.BadRequest.Newf("节点%s没有同意的顺序流, 请指定edgeId", sourceNode.Label)
+	case 1:
+		return edges[0], nil
+	default:
+		return dto.Edge{}, util.BadRequest.Newf("节点%s有多个同意的顺序流, 请指定edgeId", sourceNode.Label)
+	}
+}
+
+// 按顺序判断条件表达式, 走第一个满足条件的顺序流
+// 都不满足的时候走默认流向, 没有标记默认流向的时候走唯一一条没有条件表达式的顺序流
+func (engine *ProcessEngine) selectConditionalEdge(sourceNode dto.Node, edges []dto.Edge) (dto.Edge, error) {
+	var defaultEdge, unconditionalEdge dto.Edge
+	unconditionalCount := 0
+	for _, edge := range edges {
+		if edge.IsDefault {
+			defaultEdge = edge
+			continue
+		}
+
+		if edge.ConditionExpression == "" {
+			unconditionalEdge = edge
+			unconditionalCount++
+			continue
+		}
+
+		ok, err := engine.ConditionJudgment(edge.ConditionExpression)
+		if err != nil {
+			return dto.Edge{}, util.BadRequest.Newf("节点[%s]的顺序流[%s]的条件表达式计算失败: %s", nodeDisplayName(sourceNode), edgeDisplayName(edge), err.Error())
+		}
+		if ok {
+			return edge, nil
+		}
+	}
+
+	switch {
+	case defaultEdge.Id != "":
+		return defaultEdge, nil
+	case unconditionalCount == 1:
+		return unconditionalEdge, nil
+	case unconditionalCount > 1:
+		return dto.Edge{}, util.BadRequest.Newf("节点%s有多个没有条件表达式的顺序流, 请标记默认流向或者指定edgeId", nodeDisplayName(sourceNode))
+	default:
+		return dto.Edge{}, util.BadRequest.Newf("节点%s没有符合条件的流向, 请检查提交的变量", nodeDisplayName(sourceNode))
+	}
+}
+
+// 带有条件表达式或者标记为默认流向的顺序流, 需要根据条件进行选择
+func isConditionalEdge(edge dto.Edge) bool {
+	return edge.ConditionExpression != "" || edge.IsDefault
+}
+
+// 更新流程实例, 通过版本号进行乐观锁控制
+// 如果流程实例在加载之后已经被其他人修改过了, 返回Conflict错误
+func (engine *ProcessEngine) UpdateInstance(toUpdate map[string]interface{}) error {
+	currentVersion := engine.ProcessInstance.Version
+	toUpdate["version"] = currentVersion + 1
+
+	result := engine.tx.
+		Model(&engine.ProcessInstance).
+		Where("version = ?", currentVersion).
+		Updates(toUpdate)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return util.Conflict.New("当前流程已经被其他人处理, 请刷新后重试")
+	}
+
+	return nil
+}
@@ -18,17 +18,11 @@ func (engine *ProcessEngine) CreateHistory(remark string, isDenied bool) error {
 	// 源节点不为【开始事件】的，获取上一条的流转历史的CreateTime来计算CostDuration
 	duration := "0小时 0分钟"
 	if engine.sourceNode.Clazz != constant.START {
-		var lastCirculation model.CirculationHistory
-		err := engine.tx.
-			Where("process_instance_id = ?", engine.ProcessInstance.Id).
-			Order("create_time desc").
-			Select("create_time").
-			First(&lastCirculation).
-			Error
+		var err error
+		duration, err = engine.lastCirculationDuration()
 		if err != nil {
 			return err
 		}
-		duration = util.FmtDuration(time.Since(lastCirculation.CreateTime))
 	}
 
 	// 根据不同的类型取不同的值
@@ -77,3 +71,49 @@ func (engine *ProcessEngine) CreateHistory(remark string, isDenied bool) error {
 
 	return err
 }
+
+// 创建管理员操作的流转历史记录, 处理人记录为管理员
+func (engine *ProcessEngine) CreateAdminHistory(circulation string, sourceId string, sourceState string, targetId string, remark string) error {
+	duration, err := engine.lastCirculationDuration()
+	if err != nil {
+		return err
+	}
+
+	cirHistory := model.CirculationHistory{
+		AuditableBase: model.AuditableBase{
+			CreateBy: engine.userIdentifier,
+			UpdateBy: engine.userIdentifier,
+		},
+		Title:             engine.ProcessInstance.Title,
+		ProcessInstanceId: engine.ProcessInstance.Id,
+		SourceState:       sourceState,
+		SourceId:          sourceId,
+		TargetId:          targetId,
+		Circulation:       circulation,
+		ProcessorId:       engine.userIdentifier,
+		CostDuration:      duration,
+		Remarks:           remark,
+		IsAdmin:           true,
+	}
+
+	return engine.tx.
+		Model(&model.CirculationHistory{}).
+		Create(&cirHistory).
+		Error
+}
+
+// 获取上一条的流转历史到现在的时长
+func (engine *ProcessEngine) lastCirculationDuration() (string, error) {
+	var lastCirculation model.CirculationHistory
+	err := engine.tx.
+		Where("process_instance_id = ?", engine.ProcessInstance.Id).
+		Order("create_time desc").
+		Select("create_time").
+		First(&lastCirculation).
+		Error
+	if err != nil {
+		return "", err
+	}
+
+	return util.FmtDuration(time.Since(lastCirculation.CreateTime)), nil
+}
@@ -0,0 +1,156 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/28 20:36
+ * @Desc: 根据组织架构(部门和汇报关系)确定处理人的相关方法
+ */
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"workflow/src/global"
+	"workflow/src/model"
+	"workflow/src/model/dto"
+	"workflow/src/util"
+)
+
+// 根据组织架构类型的处理人配置获取处理人
+// initiatorManager: 发起人的直属上级
+// initiatorNthManager: 发起人的第N级上级, assignValue[0]为N
+// departmentLeader: assignValue中部门的负责人, 没有配置assignValue的时候为发起人所在部门的负责人
+// 结果为空的时候使用assignBackup, 没有配置assignBackup的返回错误
+func (engine *ProcessEngine) GetUserIdsByOrganization(node dto.Node) ([]string, error) {
+	var (
+		processors []string
+		reason     string
+		err        error
+	)
+	initiator := engine.ProcessInstance.CreateBy
+
+	switch node.AssignType {
+	case "initiatorManager":
+		processors, reason, err = engine.getNthManager(initiator, 1)
+	case "initiatorNthManager":
+		level, convErr := assignLevel(node)
+		if convErr != nil {
+			return nil, convErr
+		}
+		processors, reason, err = engine.getNthManager(initiator, level)
+	case "departmentLeader":
+		departmentIds := node.AssignValue
+		if len(departmentIds) == 0 {
+			var user model.User
+			err = global.BankDb.
+				Where("identifier = ?", initiator).
+				Where("tenant_id = ?", engine.tenantId).
+				Select("department_identifier").
+				First(&user).
+				Error
+			if err != nil || user.DepartmentIdentifier == "" {
+				reason = "发起人没有所属的部门"
+				err = nil
+				break
+			}
+			departmentIds = []string{user.DepartmentIdentifier}
+		}
+		processors, reason, err = engine.getDepartmentLeaders(departmentIds)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(processors) == 0 {
+		if len(node.AssignBackup) > 0 {
+			return node.AssignBackup, nil
+		}
+		return nil, util.BadRequest.Newf("节点[%s]的处理人为空: %s, 并且没有配置备用处理人", nodeDisplayName(node), reason)
+	}
+
+	return processors, nil
+}
+
+// 是否是根据组织架构计算处理人的类型
+func isOrganizationAssignType(assignType string) bool {
+	switch assignType {
+	case "initiatorManager", "initiatorNthManager", "departmentLeader":
+		return true
+	default:
+		return false
+	}
+}
+
+// 第N级上级的级数, 必须是正整数
+func assignLevel(node dto.Node) (int, error) {
+	if len(node.AssignValue) == 0 {
+		return 0, util.BadRequest.Newf("节点[%s]需要在assignValue中配置上级的级数", nodeDisplayName(node))
+	}
+
+	level, err := strconv.Atoi(strings.TrimSpace(node.AssignValue[0]))
+	if err != nil || level <= 0 {
+		return 0, util.BadRequest.Newf("节点[%s]的上级级数必须是正整数, 当前为: %s", nodeDisplayName(node), node.AssignValue[0])
+	}
+
+	return level, nil
+}
+
+// 沿着汇报关系向上获取第N级上级, 找不到的时候返回原因
+func (engine *ProcessEngine) getNthManager(userIdentifier string, level int) ([]string, string, error) {
+	visited := map[string]bool{userIdentifier: true}
+	current := userIdentifier
+	for i := 1; i <= level; i++ {
+		var user model.User
+		err := global.BankDb.
+			Where("identifier = ?", current).
+			Where("tenant_id = ?", engine.tenantId).
+			Select("manager_identifier").
+			First(&user).
+			Error
+		if err != nil || user.ManagerIdentifier == "" {
+			return nil, fmt.Sprintf("用户%s没有第%d级上级", userIdentifier, i), nil
+		}
+
+		// 汇报关系出现循环的时候停止
+		if visited[user.ManagerIdentifier] {
+			return nil, fmt.Sprintf("用户%s的汇报关系存在循环", userIdentifier), nil
+		}
+		visited[user.ManagerIdentifier] = true
+		current = user.ManagerIdentifier
+	}
+
+	return []string{current}, "", nil
+}
+
+// 获取部门的负责人, 部门没有负责人的时候使用上级部门的负责人
+func (engine *ProcessEngine) getDepartmentLeaders(departmentIds []string) ([]string, string, error) {
+	var departments []model.Department
+	err := global.BankDb.
+		Where("tenant_id = ?", engine.tenantId).
+		Find(&departments).
+		Error
+	if err != nil {
+		return nil, "", err
+	}
+
+	departmentMap := make(map[string]model.Department, len(departments))
+	for _, department := range departments {
+		departmentMap[department.Identifier] = department
+	}
+
+	leaders := make([]string, 0, len(departmentIds))
+	for _, departmentId := range departmentIds {
+		visited := make(map[string]bool)
+		current, ok := departmentMap[departmentId]
+		for ok && !visited[current.Identifier] && current.LeaderIdentifier == "" {
+			visited[current.Identifier] = true
+			current, ok = departmentMap[current.ParentIdentifier]
+		}
+		if !ok || current.LeaderIdentifier == "" {
+			return nil, fmt.Sprintf("部门%s及其上级部门都没有负责人", departmentId), nil
+		}
+		leaders = append(leaders, current.LeaderIdentifier)
+	}
+
+	return distinctStrings(leaders), "", nil
+}
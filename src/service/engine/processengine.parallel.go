@@ -144,9 +144,11 @@ func (engine *ProcessEngine) UpdateInstanceStateForParallel(mergedStates dto.Sta
 		"variables":      engine.ProcessInstance.Variables,
 	}
 
-	err := engine.tx.Model(&engine.ProcessInstance).
-		Updates(toUpdate).
-		Error
+	err := engine.UpdateInstance(toUpdate)
+	if err != nil {
+		return err
+	}
 
-	return err
+	// 同步待办任务
+	return engine.SyncTasks(mergedStates)
 }
@@ -8,7 +8,10 @@ package engine
 import (
 	"fmt"
 
+	"gorm.io/gorm"
+
 	"workflow/src/model"
+	"workflow/src/util"
 )
 
 // 创建实例化相关信息
@@ -19,8 +22,24 @@ func (engine *ProcessEngine) CreateProcessInstance() error {
 		return fmt.Errorf("创建工单失败，%v", err.Error())
 	}
 
+	// 生成初始的待办任务
+	err = engine.SyncTasks(engine.ProcessInstance.State)
+	if err != nil {
+		return fmt.Errorf("生成待办任务失败，%v", err.Error())
+	}
+
 	// 创建历史记录
 	initialNode, _ := engine.GetInitialNode()
+
+	// 发起时的变量都当作新增的变量
+	for _, variable := range util.UnmarshalToInstanceVariables(engine.ProcessInstance.Variables) {
+		engine.addVariableChange(variable.Name, nil, variable.Value)
+	}
+	err = engine.SaveVariableChanges(initialNode)
+	if err != nil {
+		return fmt.Errorf("保存变量变更历史失败，%v", err.Error())
+	}
+
 	nextNodes, _ := engine.GetTargetNodes(initialNode)
 	nextNode := nextNodes[0] // 开始节点后面只会直连一个节点
 	engine.SetCurrentNodeEdgeInfo(&initialNode, nil, &nextNode)
@@ -29,10 +48,10 @@ func (engine *ProcessEngine) CreateProcessInstance() error {
 		return fmt.Errorf("新建历史记录失败，%v", err.Error())
 	}
 
-	// 更新process_definition表的提交数量统计
+	// 更新process_definition表的提交数量统计, 在数据库中累加避免并发创建时丢失
 	err = engine.tx.Model(&model.ProcessDefinition{}).
 		Where("id = ?", engine.ProcessInstance.ProcessDefinitionId).
-		Update("submit_count", engine.ProcessDefinition.SubmitCount+1).Error
+		Update("submit_count", gorm.Expr("submit_count + 1")).Error
 	if err != nil {
 		return fmt.Errorf("更新流程提交数量统计失败，%v", err.Error())
 	}
@@ -0,0 +1,182 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/13 10:15
+ * @Desc: 待办任务的相关方法
+ */
+package engine
+
+import (
+	"time"
+
+	"workflow/src/global/constant"
+	"workflow/src/model"
+	"workflow/src/model/dto"
+	"workflow/src/service/event"
+	"workflow/src/util"
+)
+
+// 根据最新的states同步待办任务
+// 1. 当前用户在当前处理节点上的任务标记为已处理
+// 2. 不在最新states中的其他待办任务标记为已取消(比如或签其他人已经处理了)
+// 3. 最新states中新出现的处理人生成新的待办任务
+// 4. 新进入的配置了抄送的节点生成抄送
+func (engine *ProcessEngine) SyncTasks(newStates dto.StateArray) error {
+	var pendingTasks []model.Task
+	err := engine.tx.
+		Where("process_instance_id = ?", engine.ProcessInstance.Id).
+		Where("kind = ?", constant.TaskKindApproval).
+		Where("status = ?", constant.TaskPending).
+		Find(&pendingTasks).
+		Error
+	if err != nil {
+		return err
+	}
+
+	// 最新states中待处理的 节点+处理人
+	newTaskStates := make(map[string]dto.State)
+	for _, state := range newStates {
+		for _, processor := range state.UnCompletedProcessor {
+			if processor == "" {
+				continue
+			}
+			newTaskStates[taskKey(state.Id, processor)] = state
+		}
+	}
+
+	now := time.Now().Local()
+	existTasks := make(map[string]bool, len(pendingTasks))
+	for _, task := range pendingTasks {
+		key := taskKey(task.NodeId, task.Assignee)
+		toUpdate := map[string]interface{}{}
+		switch {
+		case task.NodeId == engine.taskNodeId && task.Assignee == engine.userIdentifier:
+			toUpdate["status"] = constant.TaskCompleted
+			toUpdate["outcome"] = engine.taskOutcome
+			toUpdate["complete_time"] = now
+		case newTaskStates[key].Id == "":
+			toUpdate["status"] = constant.TaskCancelled
+			toUpdate["complete_time"] = now
+		default:
+			existTasks[key] = true
+			continue
+		}
+
+		err = engine.tx.
+			Model(&model.Task{}).
+			Where("id = ?", task.Id).
+			Updates(toUpdate).
+			Error
+		if err != nil {
+			return err
+		}
+		engine.addTaskEvent(event.TaskRemoved, task)
+	}
+
+	// 新的待办任务
+	newTasks := make([]model.Task, 0)
+	for _, state := range newStates {
+		for _, processor := range state.UnCompletedProcessor {
+			key := taskKey(state.Id, processor)
+			if processor == "" || existTasks[key] {
+				continue
+			}
+			existTasks[key] = true
+
+			newTasks = append(newTasks, model.Task{
+				ProcessInstanceId:   engine.ProcessInstance.Id,
+				ProcessDefinitionId: engine.ProcessInstance.ProcessDefinitionId,
+				NodeId:              state.Id,
+				NodeLabel:           state.Label,
+				Assignee:            processor,
+				Status:              constant.TaskPending,
+				Kind:                constant.TaskKindApproval,
+				IsCounterSign:       state.IsCounterSign,
+				TenantId:            engine.tenantId,
+				CreateTime:          now,
+			})
+		}
+	}
+	if len(newTasks) > 0 {
+		err = engine.tx.Model(&model.Task{}).Create(&newTasks).Error
+		if err != nil {
+			return err
+		}
+
+		for _, task := range newTasks {
+			engine.addTaskEvent(event.TaskCreated, task)
+		}
+	}
+
+	return engine.createCcTasks(now)
+}
+
+// 新进入的节点配置了抄送的, 给抄送的用户生成抄送任务, 同一节点未读的抄送不重复生成
+func (engine *ProcessEngine) createCcTasks(now time.Time) error {
+	ccNodes := engine.ccNodes
+	engine.ccNodes = nil
+
+	ccTasks := make([]model.Task, 0)
+	for _, node := range ccNodes {
+		users := append([]string{}, node.CcUsers...)
+		if len(node.CcRoles) > 0 {
+			roleUsers, err := engine.GetUserIdsByRoleIds(node.CcRoles)
+			if err != nil {
+				return err
+			}
+			users = append(users, roleUsers...)
+		}
+
+		var unreadUsers []string
+		err := engine.tx.Model(&model.Task{}).
+			Where("process_instance_id = ?", engine.ProcessInstance.Id).
+			Where("node_id = ?", node.Id).
+			Where("kind = ?", constant.TaskKindCc).
+			Where("status = ?", constant.TaskCcUnread).
+			Pluck("assignee", &unreadUsers).
+			Error
+		if err != nil {
+			return err
+		}
+
+		for _, user := range distinctStrings(users) {
+			if user == "" || util.SliceAnyString(unreadUsers, user) {
+				continue
+			}
+			ccTasks = append(ccTasks, model.Task{
+				ProcessInstanceId:   engine.ProcessInstance.Id,
+				ProcessDefinitionId: engine.ProcessInstance.ProcessDefinitionId,
+				NodeId:              node.Id,
+				NodeLabel:           node.Label,
+				Assignee:            user,
+				Status:              constant.TaskCcUnread,
+				Kind:                constant.TaskKindCc,
+				TenantId:            engine.tenantId,
+				CreateTime:          now,
+			})
+		}
+	}
+	if len(ccTasks) == 0 {
+		return nil
+	}
+
+	err := engine.tx.Model(&model.Task{}).Create(&ccTasks).Error
+	if err != nil {
+		return err
+	}
+
+	for _, task := range ccTasks {
+		engine.addTaskEvent(event.TaskCc, task)
+	}
+
+	return nil
+}
+
+// 设置当前用户处理的节点和处理结果, 同步待办任务时会将其标记为已处理
+func (engine *ProcessEngine) SetTaskOutcome(nodeId string, outcome string) {
+	engine.taskNodeId = nodeId
+	engine.taskOutcome = outcome
+}
+
+func taskKey(nodeId string, assignee string) string {
+	return nodeId + "|" + assignee
+}
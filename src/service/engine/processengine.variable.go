@@ -0,0 +1,55 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/21 10:05
+ * @Desc: 流程变量变更历史的相关方法
+ */
+package engine
+
+import (
+	"bytes"
+
+	"gorm.io/datatypes"
+
+	"workflow/src/model"
+	"workflow/src/model/dto"
+	"workflow/src/util"
+)
+
+// 记录一个变量的变更, 值没有发生变化的不记录
+func (engine *ProcessEngine) addVariableChange(name string, oldValue datatypes.JSON, newValue interface{}) {
+	newJson := util.MarshalToDbJson(newValue)
+	if oldValue != nil && bytes.Equal(oldValue, newJson) {
+		return
+	}
+
+	engine.variableChanges = append(engine.variableChanges, model.VariableHistory{
+		ProcessInstanceId: engine.ProcessInstance.Id,
+		Name:              name,
+		OldValue:          oldValue,
+		NewValue:          newJson,
+		ChangedBy:         engine.userIdentifier,
+		TenantId:          engine.tenantId,
+	})
+}
+
+// 保存待保存的变量变更历史, node为变更时所在的节点
+func (engine *ProcessEngine) SaveVariableChanges(node dto.Node) error {
+	if len(engine.variableChanges) == 0 {
+		return nil
+	}
+
+	// create_time使用数据库的默认值, 和同一个事务中的流转历史保持一致
+	for i := range engine.variableChanges {
+		engine.variableChanges[i].ProcessInstanceId = engine.ProcessInstance.Id
+		engine.variableChanges[i].NodeId = node.Id
+		engine.variableChanges[i].NodeLabel = node.Label
+	}
+
+	err := engine.tx.Create(&engine.variableChanges).Error
+	if err != nil {
+		return err
+	}
+	engine.variableChanges = nil
+
+	return nil
+}
@@ -0,0 +1,135 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/14 10:05
+ * @Desc: 流程事件的发布订阅, 用于给前端实时推送待办变化
+ */
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"workflow/src/global"
+)
+
+// 多副本之间广播流程事件的channel名称
+const NotifyChannel = "wf_events"
+
+// 事件类型
+const (
+	TaskCreated     = "task.created"     // 新的待办
+	TaskRemoved     = "task.removed"     // 待办被处理或者被取消
+	InstanceUpdated = "instance.updated" // 流程实例状态发生了变化
+	TaskCc          = "task.cc"          // 新的抄送
+)
+
+// 订阅者的缓冲区大小, 满了之后新的消息会被丢弃
+const subscriberBufferSize = 64
+
+type Message struct {
+	Type              string    `json:"type"`              // 事件类型
+	TenantId          int       `json:"tenantId"`          // 租户id
+	UserIdentifier    string    `json:"userIdentifier"`    // 接收的用户
+	ProcessInstanceId int       `json:"processInstanceId"` // 流程实例id
+	Title             string    `json:"title"`             // 流程实例标题
+	NodeId            string    `json:"nodeId,omitempty"`  // 节点id(待办相关的事件才有)
+	CreateTime        time.Time `json:"createTime"`        // 事件发生的时间
+}
+
+type hub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan Message]struct{}
+}
+
+var defaultHub = &hub{
+	subscribers: map[string]map[chan Message]struct{}{},
+}
+
+// 订阅某个租户下某个用户的事件, 使用完需要调用返回的cancel
+func Subscribe(tenantId int, userIdentifier string) (<-chan Message, func()) {
+	key := subscriberKey(tenantId, userIdentifier)
+	ch := make(chan Message, subscriberBufferSize)
+
+	defaultHub.mu.Lock()
+	if defaultHub.subscribers[key] == nil {
+		defaultHub.subscribers[key] = map[chan Message]struct{}{}
+	}
+	defaultHub.subscribers[key][ch] = struct{}{}
+	defaultHub.mu.Unlock()
+
+	cancel := func() {
+		defaultHub.mu.Lock()
+		defer defaultHub.mu.Unlock()
+		if _, exist := defaultHub.subscribers[key][ch]; !exist {
+			return
+		}
+		delete(defaultHub.subscribers[key], ch)
+		if len(defaultHub.subscribers[key]) == 0 {
+			delete(defaultHub.subscribers, key)
+		}
+		close(ch)
+	}
+
+	return ch, cancel
+}
+
+// 是否通过pg_notify在副本之间广播, 开始监听NotifyChannel之后才开启
+var broadcast int32
+
+// 开始监听NotifyChannel之后调用, 之后发布的事件会广播到所有副本
+func EnableBroadcast() {
+	atomic.StoreInt32(&broadcast, 1)
+}
+
+// 发布事件, 通过pg_notify广播到所有副本(包括当前副本), 由每个副本的监听者推送给自己的订阅者
+// 没有开启广播或者发送失败的时候只推送给当前副本的订阅者
+func Publish(messages ...Message) {
+	if atomic.LoadInt32(&broadcast) == 0 {
+		PublishLocal(messages...)
+		return
+	}
+
+	for _, message := range messages {
+		payload, err := json.Marshal(message)
+		if err == nil {
+			err = global.BankDb.Exec("select pg_notify(?, ?)", NotifyChannel, string(payload)).Error
+		}
+		if err != nil {
+			global.BankLogger.Error("发送流程事件通知失败", err)
+			PublishLocal(message)
+		}
+	}
+}
+
+// 处理通过pg_notify收到的事件
+func HandleNotification(payload string) {
+	var message Message
+	if err := json.Unmarshal([]byte(payload), &message); err != nil {
+		global.BankLogger.Error("流程事件通知的格式不正确", err)
+		return
+	}
+
+	PublishLocal(message)
+}
+
+// 推送给当前副本的订阅者, 不会阻塞
+func PublishLocal(messages ...Message) {
+	defaultHub.mu.RLock()
+	defer defaultHub.mu.RUnlock()
+
+	for _, message := range messages {
+		for ch := range defaultHub.subscribers[subscriberKey(message.TenantId, message.UserIdentifier)] {
+			select {
+			case ch <- message:
+			default:
+			}
+		}
+	}
+}
+
+func subscriberKey(tenantId int, userIdentifier string) string {
+	return fmt.Sprintf("%d|%s", tenantId, userIdentifier)
+}
@@ -19,10 +19,25 @@ import (
 
 func ListHistory(r *request.HistoryListRequest, c echo.Context) (*response.PagingResponse, error) {
 	var (
-		histories   []model.CirculationHistory
-		tenantId, _ = util.GetWorkContext(c)
+		histories                []model.CirculationHistory
+		tenantId, userIdentifier = util.GetWorkContext(c)
 	)
 
+	// 必须有权限查看流程实例才能查看流转历史
+	_, err := getVisibleInstance(r.Id, userIdentifier, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = shared.ResolveSort(&r.PagingRequest, map[string]string{
+		"":            "circulation_history.id",
+		"id":          "circulation_history.id",
+		"create_time": "circulation_history.create_time",
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	db := global.BankDb.
 		Model(&model.ProcessInstance{}).
 		Where("tenant_id = ?", tenantId).
@@ -39,6 +54,9 @@ func ListHistory(r *request.HistoryListRequest, c echo.Context) (*response.Pagin
 	}
 
 	if r.Keyword != "" {
+		if err := util.ValidateKeyword(r.Keyword); err != nil {
+			return nil, err
+		}
 		db = db.Where("title ~ ?", r.Keyword)
 	}
 
@@ -46,7 +64,7 @@ func ListHistory(r *request.HistoryListRequest, c echo.Context) (*response.Pagin
 	db.Count(&count)
 
 	db = shared.ApplyPaging(db, &r.PagingRequest)
-	err := db.Select("circulation_history.*").Scan(&histories).Error
+	err = db.Select("circulation_history.*").Scan(&histories).Error
 
 	return &response.PagingResponse{
 		TotalCount:   count,
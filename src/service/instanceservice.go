@@ -7,7 +7,7 @@ package service
 
 import (
 	"errors"
-	"fmt"
+	"time"
 
 	. "github.com/ahmetb/go-linq/v3"
 	"github.com/labstack/echo/v4"
@@ -28,7 +28,6 @@ import (
 func CreateProcessInstance(r *request.ProcessInstanceRequest, c echo.Context) (*model.ProcessInstance, error) {
 	var (
 		processDefinition        model.ProcessDefinition // 流程模板
-		tx                       = global.BankDb.Begin() // 开启事务
 		tenantId, userIdentifier = util.GetWorkContext(c)
 	)
 
@@ -37,6 +36,7 @@ func CreateProcessInstance(r *request.ProcessInstanceRequest, c echo.Context) (*
 	if err != nil {
 		return nil, util.BadRequest.New(err)
 	}
+	util.InferVariableTypes(r.Variables)
 
 	// 查询对应的流程模板
 	err = global.BankDb.
@@ -48,19 +48,32 @@ func CreateProcessInstance(r *request.ProcessInstanceRequest, c echo.Context) (*
 		return nil, err
 	}
 
+	// 检查是否有发起权限
+	err = CheckDefinitionStarter(&processDefinition, userIdentifier, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := global.BankDb.Begin() // 开启事务
+
 	// 初始化流程引擎
 	instanceEngine, err := engine.NewProcessEngine(processDefinition, r.ToProcessInstance(userIdentifier, tenantId), userIdentifier, tenantId, tx)
 	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
-	// 将初始状态赋值给当前的流程实例
+	// 将初始状态赋值给当前的流程实例, 计算处理人失败(比如处理人变量为空、找不到上级)的时候回滚
 	if currentInstanceState, err := instanceEngine.GetInstanceInitialState(); err != nil {
+		tx.Rollback()
 		return nil, err
 	} else {
 		instanceEngine.ProcessInstance.State = currentInstanceState
 	}
 
+	// 流程实例的分类跟随流程定义
+	instanceEngine.ProcessInstance.ClassifyId = processDefinition.ClassifyId
+
 	// TODO 这里判断下一步是排他网关等情况
 
 	// 更新instance的关联人
@@ -72,6 +85,7 @@ func CreateProcessInstance(r *request.ProcessInstanceRequest, c echo.Context) (*
 		tx.Rollback()
 	} else {
 		tx.Commit()
+		instanceEngine.PublishEvents()
 	}
 
 	return &instanceEngine.ProcessInstance, err
@@ -79,46 +93,21 @@ func CreateProcessInstance(r *request.ProcessInstanceRequest, c echo.Context) (*
 
 // 获取单个ProcessInstance
 func GetProcessInstance(r *request.GetInstanceRequest, c echo.Context) (*response.ProcessInstanceResponse, error) {
-	var (
-		instance                 model.ProcessInstance
-		tenantId, userIdentifier = util.GetWorkContext(c)
-	)
-	err := global.BankDb.
-		Where("id=?", r.Id).
-		Where("tenant_id = ?", tenantId).
-		First(&instance).
-		Error
+	tenantId, userIdentifier := util.GetWorkContext(c)
+
+	// 必须有权限才能看到
+	instance, err := getVisibleInstance(r.Id, userIdentifier, tenantId)
 	if err != nil {
 		return nil, err
 	}
 
-	// 必须是相关的才能看到
-	exist := false
-	for _, state := range instance.State {
-		for _, processor := range state.Processor {
-			if processor == userIdentifier {
-				exist = true
-				break
-			}
-		}
-	}
-	if !exist {
-		exist = From(instance.RelatedPerson).AnyWith(func(i interface{}) bool {
-			return i.(string) == userIdentifier
-		})
-
-		if !exist {
-			return nil, util.NotFound.New("记录不存在")
-		}
-	}
-
 	resp := response.ProcessInstanceResponse{
-		ProcessInstance: instance,
+		ProcessInstance: *instance,
 	}
 
 	// 包括流程链路
 	if r.IncludeProcessTrain {
-		trainNodes, err := GetProcessTrain(&instance, instance.Id, c)
+		trainNodes, err := GetProcessTrain(instance, instance.Id, c)
 		if err != nil {
 			return nil, err
 		}
@@ -128,63 +117,219 @@ func GetProcessInstance(r *request.GetInstanceRequest, c echo.Context) (*respons
 	return &resp, nil
 }
 
+// 获取当前用户对流程实例可以进行的操作
+func GetInstanceActions(instanceId int, c echo.Context) (*response.InstanceActionsResponse, error) {
+	tenantId, userIdentifier := util.GetWorkContext(c)
+
+	// 必须有权限才能看到
+	instance, err := getVisibleInstance(instanceId, userIdentifier, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	definition, err := GetDefinition(instance.ProcessDefinitionId, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	instanceEngine, err := engine.NewProcessEngine(*definition, *instance, userIdentifier, tenantId, global.BankDb)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := GetUserRoleIdentifiers(userIdentifier, tenantId)
+	if err != nil {
+		return nil, err
+	}
+	isRunning := !instance.IsEnd && !instance.IsDenied
+	isAdmin := isTenantAdmin(roles)
+
+	return &response.InstanceActionsResponse{
+		ProcessInstanceId: instance.Id,
+		IsEnd:             instance.IsEnd,
+		IsDenied:          instance.IsDenied,
+		Nodes:             instanceEngine.GetNodeActions(),
+		CanTransfer:       isAdmin && isRunning,
+		CanJump:           isAdmin,
+		CanTerminate:      isAdmin && isRunning,
+	}, nil
+}
+
+// 流程实例列表可用的排序字段
+var instanceSortColumns = map[string]string{
+	"":                      "update_time",
+	"id":                    "id",
+	"title":                 "title",
+	"priority":              "priority",
+	"create_time":           "create_time",
+	"update_time":           "update_time",
+	"process_definition_id": "process_definition_id",
+}
+
 // 获取ProcessInstance列表
 func ListProcessInstance(r *request.InstanceListRequest, c echo.Context) (*response.PagingResponse, error) {
 	var (
 		instances                []model.ProcessInstance
-		db                       *gorm.DB
 		tenantId, userIdentifier = util.GetWorkContext(c)
 	)
 
-	switch r.Type {
-	case constant.I_MyToDo:
-		return getTodoInstances(r, userIdentifier, tenantId)
-	default:
-		db = global.BankDb.Model(&model.ProcessInstance{}).
-			Where("tenant_id = ?", tenantId)
+	err := shared.ResolveSort(&r.PagingRequest, instanceSortColumns)
+	if err != nil {
+		return nil, err
 	}
 
+	db := global.BankDb.Model(&model.ProcessInstance{}).
+		Where("process_instance.tenant_id = ?", tenantId)
+
 	// 根据type的不同有不同的逻辑
-	switch r.Type {
+	db, err = applyInstanceListType(db, r.Type, tenantId, userIdentifier)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err = applyInstanceFilters(db, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	db.Count(&count)
+
+	db = shared.ApplyPaging(db, &r.PagingRequest)
+	err = db.Find(&instances).Error
+
+	return &response.PagingResponse{
+		TotalCount:   count,
+		CurrentCount: int64(len(instances)),
+		Data:         &instances,
+	}, err
+}
+
+// 各个列表类型对应的查询条件
+func applyInstanceListType(db *gorm.DB, listType int, tenantId int, userIdentifier string) (*gorm.DB, error) {
+	switch listType {
 	case constant.I_MyToDo:
-		//db = db.Joins("cross join jsonb_array_elements(state) as elem").Where(fmt.Sprintf("elem -> 'processor' @> '[%v]'", userIdentifier))
-		break
+		db = db.Where("is_end = false and is_denied = false").
+			Where("exists (select 1 from wf.task where task.process_instance_id = process_instance.id and task.tenant_id = ? and task.assignee = ? and task.status = ?)",
+				tenantId, userIdentifier, constant.TaskPending)
 	case constant.I_ICreated:
 		db = db.Where("create_by=?", userIdentifier)
-		break
 	case constant.I_IRelated:
-		db = db.Joins("cross join jsonb_array_elements(state) as elem").
-			Where(fmt.Sprintf("related_person @> Array[%s] or elem -> 'processor' @> '[%s]'", userIdentifier, userIdentifier))
-		break
+		db = db.Where("? = any(related_person) or exists (select 1 from wf.task where task.process_instance_id = process_instance.id and task.tenant_id = ? and task.assignee = ?)",
+			userIdentifier, tenantId, userIdentifier)
+	case constant.I_IHandled:
+		// 开始节点的流转记录是发起人创建的, 管理员操作的记录也不是审批, 都不算处理过
+		db = db.Where("exists (select 1 from wf.circulation_history where circulation_history.process_instance_id = process_instance.id and circulation_history.processor_id = ? and circulation_history.circulation <> ? and circulation_history.is_admin is not true)",
+			userIdentifier, "开始")
 	case constant.I_All:
-		break
+		// 只返回当前用户有权限查看的
+		return applyInstanceVisibility(db, userIdentifier, tenantId)
 	default:
 		return nil, util.BadRequest.New("type不合法")
 	}
 
+	return db, nil
+}
+
+// 获取各个列表类型的数量(用于待办角标等)
+func CountProcessInstances(c echo.Context) (*response.InstanceCountResponse, error) {
+	tenantId, userIdentifier := util.GetWorkContext(c)
+
+	counts := make(map[int]int64)
+	for _, listType := range []int{constant.I_MyToDo, constant.I_ICreated, constant.I_IRelated, constant.I_IHandled, constant.I_All} {
+		db := global.BankDb.Model(&model.ProcessInstance{}).
+			Where("process_instance.tenant_id = ?", tenantId)
+		db, err := applyInstanceListType(db, listType, tenantId, userIdentifier)
+		if err != nil {
+			return nil, err
+		}
+
+		var count int64
+		err = db.Count(&count).Error
+		if err != nil {
+			return nil, err
+		}
+		counts[listType] = count
+	}
+
+	return &response.InstanceCountResponse{
+		MyToDo:   counts[constant.I_MyToDo],
+		ICreated: counts[constant.I_ICreated],
+		IRelated: counts[constant.I_IRelated],
+		IHandled: counts[constant.I_IHandled],
+		All:      counts[constant.I_All],
+	}, nil
+}
+
+// 流程实例列表的筛选条件
+func applyInstanceFilters(db *gorm.DB, r *request.InstanceListRequest) (*gorm.DB, error) {
 	if r.Keyword != "" {
+		if err := util.ValidateKeyword(r.Keyword); err != nil {
+			return nil, err
+		}
 		db = db.Where("title ~ ?", r.Keyword)
 	}
 
-	var count int64
-	db.Count(&count)
+	if r.ProcessDefinitionId != 0 {
+		db = db.Where("process_definition_id = ?", r.ProcessDefinitionId)
+	}
 
-	db = shared.ApplyPaging(db, &r.PagingRequest)
-	err := db.Find(&instances).Error
+	if r.ClassifyId != 0 {
+		db = db.Where("classify_id = ?", r.ClassifyId)
+	}
 
-	return &response.PagingResponse{
-		TotalCount:   count,
-		CurrentCount: int64(len(instances)),
-		Data:         &instances,
-	}, err
+	if r.Priority != 0 {
+		db = db.Where("priority = ?", r.Priority)
+	}
+
+	if r.Initiator != "" {
+		db = db.Where("create_by = ?", r.Initiator)
+	}
+
+	if r.CurrentNodeId != "" {
+		db = db.Where("state @> ?", util.MarshalToString([]map[string]string{{"id": r.CurrentNodeId}}))
+	}
+
+	switch r.Status {
+	case 0:
+	case constant.InstanceRunning:
+		db = db.Where("is_end = false and is_denied = false")
+	case constant.InstanceEnded:
+		db = db.Where("is_end = true")
+	case constant.InstanceDenied:
+		db = db.Where("is_denied = true")
+	default:
+		return nil, util.BadRequest.New("status不合法")
+	}
+
+	// 时间范围
+	timeRanges := []struct {
+		value    string
+		operator string
+	}{
+		{r.CreateTimeStart, "create_time >= ?"},
+		{r.CreateTimeEnd, "create_time <= ?"},
+		{r.UpdateTimeStart, "update_time >= ?"},
+		{r.UpdateTimeEnd, "update_time <= ?"},
+	}
+	for _, timeRange := range timeRanges {
+		if timeRange.value == "" {
+			continue
+		}
+		t, err := util.ParseTime(timeRange.value)
+		if err != nil {
+			return nil, util.BadRequest.New(err)
+		}
+		db = db.Where(timeRange.operator, t)
+	}
+
+	return db, nil
 }
 
 // 处理/审批ProcessInstance
+// 和其他人同时处理产生冲突的时候, 会基于最新的流程实例重新处理
 func HandleProcessInstance(r *request.HandleInstancesRequest, c echo.Context) (*model.ProcessInstance, error) {
-	var (
-		tx                       = global.BankDb.Begin() // 开启事务
-		tenantId, userIdentifier = util.GetWorkContext(c)
-	)
+	tenantId, userIdentifier := util.GetWorkContext(c)
 
 	// 验证变量是否符合要求
 	err := validateVariables(r.Variables)
@@ -192,48 +337,168 @@ func HandleProcessInstance(r *request.HandleInstancesRequest, c echo.Context) (*
 		return nil, err
 	}
 
+	var instance *model.ProcessInstance
+	err = retryOnConflict(func() error {
+		instance, err = handleProcessInstance(r, userIdentifier, tenantId)
+		return err
+	})
+
+	return instance, err
+}
+
+func handleProcessInstance(r *request.HandleInstancesRequest, userIdentifier string, tenantId int) (*model.ProcessInstance, error) {
+	tx := global.BankDb.Begin() // 开启事务
+
 	// 流程实例引擎
 	processEngine, err := engine.NewProcessEngineByInstanceId(r.ProcessInstanceId, userIdentifier, tenantId, tx)
 	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
+	// 合并最新的变量, 顺序流的条件表达式会用到审批时提交的变量
+	err = processEngine.MergeVariables(r.Variables)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// 确定实际要走的顺序流, 没有指定edgeId或者顺序流带有条件表达式的时候根据条件选择
+	edge, err := processEngine.ResolveHandleEdge(r.EdgeId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if edge.Id != r.EdgeId {
+		handleRequest := *r
+		handleRequest.EdgeId = edge.Id
+		r = &handleRequest
+	}
+
 	// 验证合法性(1.edgeId是否合法 2.当前用户是否有权限处理)
 	err = processEngine.ValidateHandleRequest(r)
 	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
-	// 合并最新的变量
-	processEngine.MergeVariables(r.Variables)
-
 	// 处理操作, 判断这里的原因是因为上面都不会进行数据库改动操作
 	err = processEngine.Handle(r)
 	if err != nil {
 		tx.Rollback()
 	} else {
 		tx.Commit()
+		processEngine.PublishEvents()
 	}
 
 	return &processEngine.ProcessInstance, err
 }
 
+// 批量审批的最大数量
+const maxBatchHandleItems = 100
+
+// 批量审批/否决, 每一项在单独的事务中处理, 部分失败不影响其他项
+func BatchHandleProcessInstances(r *request.BatchHandleInstancesRequest, c echo.Context) (*response.BatchHandleResponse, error) {
+	if len(r.Items) == 0 {
+		return nil, util.BadRequest.New("需要处理的流程实例不能为空")
+	}
+	if len(r.Items) > maxBatchHandleItems {
+		return nil, util.BadRequest.Newf("一次最多处理%d个流程实例", maxBatchHandleItems)
+	}
+
+	tenantId, userIdentifier := util.GetWorkContext(c)
+	result := response.BatchHandleResponse{
+		Items: make([]response.BatchHandleItemResult, 0, len(r.Items)),
+	}
+	for _, item := range r.Items {
+		remarks := item.Remarks
+		if remarks == "" {
+			remarks = r.Remarks
+		}
+
+		var (
+			instance *model.ProcessInstance
+			err      error
+		)
+		err = retryOnConflict(func() error {
+			if item.IsDeny {
+				instance, err = batchDenyProcessInstance(item.ProcessInstanceId, remarks, userIdentifier, tenantId)
+			} else {
+				instance, err = handleProcessInstance(&request.HandleInstancesRequest{
+					EdgeId:            item.EdgeId,
+					ProcessInstanceId: item.ProcessInstanceId,
+					Remarks:           remarks,
+					Variables:         []model.InstanceVariable{},
+				}, userIdentifier, tenantId)
+			}
+			return err
+		})
+
+		itemResult := response.BatchHandleItemResult{ProcessInstanceId: item.ProcessInstanceId}
+		if err != nil {
+			itemResult.Message = err.Error()
+			result.FailedCount++
+		} else {
+			itemResult.Success = true
+			itemResult.IsEnd = instance.IsEnd
+			itemResult.IsDenied = instance.IsDenied
+			result.SuccessCount++
+		}
+		result.Items = append(result.Items, itemResult)
+	}
+
+	return &result, nil
+}
+
+// 批量否决的时候没有指定节点, 否决当前用户待处理的节点
+func batchDenyProcessInstance(instanceId int, remarks string, userIdentifier string, tenantId int) (*model.ProcessInstance, error) {
+	processEngine, err := engine.NewProcessEngineByInstanceId(instanceId, userIdentifier, tenantId, global.BankDb)
+	if err != nil {
+		return nil, util.NotFound.New(err)
+	}
+
+	state, err := processEngine.GetCurrentUserState()
+	if err != nil {
+		return nil, err
+	}
+
+	return denyProcessInstance(&request.DenyInstanceRequest{
+		ProcessInstanceId: instanceId,
+		NodeId:            state.Id,
+		Remarks:           remarks,
+	}, userIdentifier, tenantId)
+}
+
 // 否决流程
 func DenyProcessInstance(r *request.DenyInstanceRequest, c echo.Context) (*model.ProcessInstance, error) {
+	tenantId, userIdentifier := util.GetWorkContext(c)
+
 	var (
-		tx                       = global.BankDb.Begin() // 开启事务
-		tenantId, userIdentifier = util.GetWorkContext(c)
+		instance *model.ProcessInstance
+		err      error
 	)
+	err = retryOnConflict(func() error {
+		instance, err = denyProcessInstance(r, userIdentifier, tenantId)
+		return err
+	})
+
+	return instance, err
+}
+
+func denyProcessInstance(r *request.DenyInstanceRequest, userIdentifier string, tenantId int) (*model.ProcessInstance, error) {
+	tx := global.BankDb.Begin() // 开启事务
 
 	// 流程实例引擎
 	instanceEngine, err := engine.NewProcessEngineByInstanceId(r.ProcessInstanceId, userIdentifier, tenantId, tx)
 	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
 	// 验证当前用户是否有权限处理
 	err = instanceEngine.ValidateDenyRequest(r)
 	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
@@ -243,27 +508,44 @@ func DenyProcessInstance(r *request.DenyInstanceRequest, c echo.Context) (*model
 		tx.Rollback()
 	} else {
 		tx.Commit()
+		instanceEngine.PublishEvents()
 	}
 
 	return &instanceEngine.ProcessInstance, err
 }
 
+// 乐观锁冲突时最多尝试的次数
+const conflictRetryTimes = 3
+
+// 流程实例被其他人同时修改(乐观锁冲突)的时候重试
+// 每次重试都会重新加载流程实例并重新校验, 所以已经不能处理的请求会返回校验错误而不是重复处理
+func retryOnConflict(operate func() error) error {
+	var err error
+	for i := 0; i < conflictRetryTimes; i++ {
+		err = operate()
+		if util.GetType(err) != util.Conflict {
+			return err
+		}
+		time.Sleep(time.Duration(i+1) * 50 * time.Millisecond)
+	}
+
+	return err
+}
+
 // 获取流程链(用于展示)
 func GetProcessTrain(pi *model.ProcessInstance, instanceId int, c echo.Context) ([]response.ProcessChainNode, error) {
 	var (
 		instance                 model.ProcessInstance
-		tenantId, _ = util.GetWorkContext(c)
+		tenantId, userIdentifier = util.GetWorkContext(c)
 	)
-	
-	// 1. 获取流程实例(如果为空)
+
+	// 1. 获取流程实例(如果为空), 需要检查权限
 	if pi == nil {
-		err := global.BankDb.
-			Where("id=?", instanceId).
-			Where("tenant_id = ?", tenantId).
-			First(&instance).
-			Error
+		visibleInstance, err := getVisibleInstance(instanceId, userIdentifier, tenantId)
 		if err != nil {
+			return nil, err
 		}
+		instance = *visibleInstance
 	} else {
 		instance = *pi
 	}
@@ -376,12 +658,18 @@ func GetProcessTrain(pi *model.ProcessInstance, instanceId int, c echo.Context)
 // 检查变量是否合法
 func validateVariables(variables []model.InstanceVariable) error {
 	checkedVariables := make(map[string]model.InstanceVariable, 0)
-	for _, v := range variables {
+	for i := range variables {
+		v := &variables[i]
 		// 检查是否重名
 		if _, present := checkedVariables[v.Name]; present {
-			return fmt.Errorf("当前变量名:%s 重复, 请检查", v.Name)
+			return util.BadRequest.Newf("当前变量名:%s 重复, 请检查", v.Name)
 		}
-		checkedVariables[v.Name] = v
+
+		// 按照指定的类型转换
+		if err := util.CoerceVariable(v); err != nil {
+			return util.BadRequest.New(err)
+		}
+		checkedVariables[v.Name] = *v
 	}
 
 	return nil
@@ -413,54 +701,3 @@ func getPossibleTrainNode(definitionStructure dto.Structure, currentNodeId strin
 		}
 	}
 }
-
-func getTodoInstances(r *request.InstanceListRequest, userIdentifier string, tenantId int) (*response.PagingResponse, error) {
-	countSql := fmt.Sprintf(`with base as (
-		select *,
-			jsonb_array_elements(state) as singleState
-			from wf.process_instance
-			where tenant_id = %d
-			AND is_end = false
-			AND is_denied = false
-			)
-			select count(1)
-				from base
-				where singleState -> 'processor' @> '["%s"]'
-				and singleState -> 'unCompletedProcessor' @> '["%s"]'`,
-		tenantId, userIdentifier, userIdentifier)
-	if r.Keyword != "" {
-		countSql += fmt.Sprintf(" AND title ~ '%s'", r.Keyword)
-	}
-	var c int64
-	err := global.BankDb.Raw(countSql).Scan(&c).Error
-	if err != nil {
-		return nil, err
-	}
-
-	sql := fmt.Sprintf(`with base as (
-		select *,
-			jsonb_array_elements(state) as singleState
-			from wf.process_instance
-			where tenant_id = %d
-			AND is_end = false
-			AND is_denied = false
-			)
-			select id, create_time, update_time, create_by, update_by, title, priority,
-				process_definition_id, classify_id, is_end, is_denied, state, related_person, tenant_id, variables
-				from base
-				where singleState -> 'processor' @> '["%s"]'
-				and singleState -> 'unCompletedProcessor' @> '["%s"]' `,
-		tenantId, userIdentifier, userIdentifier)
-	if r.Keyword != "" {
-		sql += fmt.Sprintf(" AND title ~ '%s'", r.Keyword)
-	}
-	sql = shared.ApplyRawPaging(sql, &r.PagingRequest)
-	var instances []model.ProcessInstance
-	err = global.BankDb.Raw(sql).Scan(&instances).Error
-
-	return &response.PagingResponse{
-		TotalCount:   c,
-		CurrentCount: int64(len(instances)),
-		Data:         &instances,
-	}, err
-}
@@ -9,10 +9,16 @@ import (
 	. "github.com/ahmetb/go-linq/v3"
 
 	"workflow/src/global"
+	"workflow/src/global/shared"
 	"workflow/src/model"
 	"workflow/src/model/request"
 )
 
+// 获取用户在当前租户下的角色, 优先从缓存中获取, 同步角色用户之后会通知所有副本使缓存失效
+func GetUserRoleIdentifiers(userIdentifier string, tenantId int) ([]string, error) {
+	return shared.GetUserRoleIdentifiers(userIdentifier, tenantId)
+}
+
 // 异步批量同步外部系统的角色用户对应关系
 func BatchSyncRoleUsers(r *request.BatchSyncUserRoleRequest, tenantId int) error {
 	go BatchSyncRoleUsersAsync(r, tenantId)
@@ -21,7 +27,7 @@ func BatchSyncRoleUsers(r *request.BatchSyncUserRoleRequest, tenantId int) error
 }
 
 func BatchSyncRoleUsersAsync(r *request.BatchSyncUserRoleRequest, tenantId int) {
-	users, roles, userRoles := r.ToDbEntities(tenantId)
+	users, roles, userRoles, departments := r.ToDbEntities(tenantId)
 
 	// 同步user
 	err := DeleteOriginUsers(users, tenantId)
@@ -36,6 +42,10 @@ func BatchSyncRoleUsersAsync(r *request.BatchSyncUserRoleRequest, tenantId int)
 	if err != nil {
 		global.BankLogger.Error("删除角色用户关联信息失败", err)
 	}
+	err = DeleteOriginDepartments(departments, tenantId)
+	if err != nil {
+		global.BankLogger.Error("删除部门信息失败", err)
+	}
 
 	// 批量创建数据
 	err = global.BankDb.
@@ -59,8 +69,20 @@ func BatchSyncRoleUsersAsync(r *request.BatchSyncUserRoleRequest, tenantId int)
 	if err != nil {
 		global.BankLogger.Error("批量更新用户角色关联关系失败", err)
 	}
+	if len(departments) > 0 {
+		err = global.BankDb.
+			Model(&model.Department{}).
+			Create(&departments).
+			Error
+		if err != nil {
+			global.BankLogger.Error("批量更新部门失败", err)
+		}
+	}
+
+	// 用户角色发生了变化, 使所有副本中当前租户的用户角色缓存失效
+	shared.InvalidateCache(shared.UserRolesCacheKey(tenantId, ""))
 
-	global.BankLogger.Infof("批量更新角色用户对应关系成功，更新用户条数:%d，更新角色条数:%d，更新关联关系条数:%d\n", len(users), len(roles), len(userRoles))
+	global.BankLogger.Infof("批量更新角色用户对应关系成功，更新用户条数:%d，更新角色条数:%d，更新关联关系条数:%d，更新部门条数:%d\n", len(users), len(roles), len(userRoles), len(departments))
 }
 
 func DeleteOriginUsers(users []model.User, tenantId int) error {
@@ -93,6 +115,25 @@ func DeleteOriginRoles(roles []model.Role, tenantId int) error {
 	return err
 }
 
+func DeleteOriginDepartments(departments []model.Department, tenantId int) error {
+	if len(departments) == 0 {
+		return nil
+	}
+
+	var departmentIds []string
+	From(departments).Select(func(i interface{}) interface{} {
+		return i.(model.Department).Identifier
+	}).ToSlice(&departmentIds)
+
+	err := global.BankDb.Model(&model.Department{}).
+		Where("identifier in ?", departmentIds).
+		Where("tenant_id = ?", tenantId).
+		Delete(model.Department{}).
+		Error
+
+	return err
+}
+
 func DeleteOriginUserRoles(userRoles []model.UserRole, tenantId int) error {
 	var userIds []string
 	var roleIds []string
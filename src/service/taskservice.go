@@ -0,0 +1,150 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/13 14:30
+ * @Desc: 待办任务
+ */
+package service
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"workflow/src/global"
+	"workflow/src/global/constant"
+	"workflow/src/global/shared"
+	"workflow/src/model"
+	"workflow/src/model/request"
+	"workflow/src/model/response"
+	"workflow/src/util"
+)
+
+// 任务列表可用的排序字段
+var taskSortColumns = map[string]string{
+	"":              "task.create_time",
+	"create_time":   "task.create_time",
+	"complete_time": "task.complete_time",
+	"id":            "task.id",
+}
+
+// 获取当前用户的审批任务列表(待办/已办)
+func ListTasks(r *request.TaskListRequest, c echo.Context) (*response.PagingResponse, error) {
+	var (
+		tasks                    []response.TaskResponse
+		tenantId, userIdentifier = util.GetWorkContext(c)
+	)
+
+	err := shared.ResolveSort(&r.PagingRequest, taskSortColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	db := global.BankDb.
+		Model(&model.Task{}).
+		Joins("inner join wf.process_instance on process_instance.id = task.process_instance_id and process_instance.deleted_at is null").
+		Where("task.tenant_id = ?", tenantId).
+		Where("task.assignee = ?", userIdentifier).
+		Where("task.kind = ?", constant.TaskKindApproval)
+
+	if r.Status != 0 {
+		db = db.Where("task.status = ?", r.Status)
+	}
+
+	if r.Keyword != "" {
+		if err := util.ValidateKeyword(r.Keyword); err != nil {
+			return nil, err
+		}
+		db = db.Where("process_instance.title ~ ?", r.Keyword)
+	}
+
+	var count int64
+	db.Count(&count)
+
+	db = shared.ApplyPaging(db, &r.PagingRequest)
+	err = db.
+		Select("task.*, process_instance.title, process_instance.priority, process_instance.create_by as initiator").
+		Scan(&tasks).
+		Error
+
+	return &response.PagingResponse{
+		TotalCount:   count,
+		CurrentCount: int64(len(tasks)),
+		Data:         &tasks,
+	}, err
+}
+
+// 获取当前用户的抄送列表
+func ListCcTasks(r *request.CcTaskListRequest, c echo.Context) (*response.PagingResponse, error) {
+	var (
+		tasks                    []response.TaskResponse
+		tenantId, userIdentifier = util.GetWorkContext(c)
+	)
+
+	err := shared.ResolveSort(&r.PagingRequest, taskSortColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	// 未读和已读是不同的状态, 可以使用 租户+处理人+状态 的索引
+	statuses := []int{constant.TaskCcUnread, constant.TaskCcRead}
+	if r.Unread {
+		statuses = []int{constant.TaskCcUnread}
+	}
+	db := global.BankDb.
+		Model(&model.Task{}).
+		Joins("inner join wf.process_instance on process_instance.id = task.process_instance_id and process_instance.deleted_at is null").
+		Where("task.tenant_id = ?", tenantId).
+		Where("task.assignee = ?", userIdentifier).
+		Where("task.status in ?", statuses)
+
+	if r.Keyword != "" {
+		if err := util.ValidateKeyword(r.Keyword); err != nil {
+			return nil, err
+		}
+		db = db.Where("process_instance.title ~ ?", r.Keyword)
+	}
+
+	var count int64
+	db.Count(&count)
+
+	db = shared.ApplyPaging(db, &r.PagingRequest)
+	err = db.
+		Select("task.*, process_instance.title, process_instance.priority, process_instance.create_by as initiator").
+		Scan(&tasks).
+		Error
+
+	return &response.PagingResponse{
+		TotalCount:   count,
+		CurrentCount: int64(len(tasks)),
+		Data:         &tasks,
+	}, err
+}
+
+// 把当前用户的抄送标记为已读
+func ReadCcTask(r *request.ReadCcTaskRequest, c echo.Context) error {
+	tenantId, userIdentifier := util.GetWorkContext(c)
+
+	var task model.Task
+	err := global.BankDb.
+		Where("id = ?", r.Id).
+		Where("tenant_id = ?", tenantId).
+		Where("assignee = ?", userIdentifier).
+		Where("kind = ?", constant.TaskKindCc).
+		First(&task).
+		Error
+	if err != nil {
+		return util.NotFound.New("抄送不存在")
+	}
+	if task.Status == constant.TaskCcRead {
+		return nil
+	}
+
+	return global.BankDb.
+		Model(&model.Task{}).
+		Where("id = ?", task.Id).
+		Updates(map[string]interface{}{
+			"status":        constant.TaskCcRead,
+			"complete_time": time.Now().Local(),
+		}).
+		Error
+}
@@ -0,0 +1,166 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/16 15:02
+ * @Desc: 租户管理
+ */
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	"workflow/src/global"
+	"workflow/src/global/shared"
+	"workflow/src/model"
+	"workflow/src/model/request"
+	"workflow/src/model/response"
+	"workflow/src/util"
+)
+
+// 获取租户详情
+func GetTenant(id int) (*model.Tenant, error) {
+	var tenant model.Tenant
+	err := global.BankDb.
+		Where("id = ?", id).
+		First(&tenant).
+		Error
+	if err != nil {
+		return nil, util.NotFound.New("租户不存在")
+	}
+
+	return &tenant, nil
+}
+
+// 验证
+func ValidateTenantRequest(r *request.TenantRequest, excludeId int) error {
+	if r.Name == "" {
+		return util.BadRequest.New("租户编码不能为空")
+	}
+
+	var c int64
+	global.BankDb.Model(&model.Tenant{}).
+		Where("name = ?", r.Name).
+		Where("id != ?", excludeId).
+		Count(&c)
+	if c != 0 {
+		return util.BadRequest.Newf("当前编码为:\"%s\"的租户已存在", r.Name)
+	}
+
+	if r.TimeZone != "" {
+		if _, err := time.LoadLocation(r.TimeZone); err != nil {
+			return util.BadRequest.Newf("时区不合法: %s", r.TimeZone)
+		}
+	}
+
+	if len(r.Settings) > 0 {
+		var settings map[string]interface{}
+		if err := json.Unmarshal(r.Settings, &settings); err != nil {
+			return util.BadRequest.New("settings必须是json对象")
+		}
+	}
+
+	return nil
+}
+
+// 创建租户
+func CreateTenant(r *request.TenantRequest) (*model.Tenant, error) {
+	tenant := r.ToTenant()
+	tenant.Id = 0
+
+	err := global.BankDb.Create(&tenant).Error
+	if err != nil {
+		global.BankLogger.Error(err)
+		return nil, util.NewError("创建失败")
+	}
+	shared.InvalidateCache(shared.TenantsCacheKey)
+
+	return &tenant, nil
+}
+
+// 更新租户, 包括修改租户编码(重命名)
+func UpdateTenant(r *request.TenantRequest) error {
+	_, err := GetTenant(r.Id)
+	if err != nil {
+		return err
+	}
+
+	tenant := r.ToTenant()
+	err = global.BankDb.
+		Model(&model.Tenant{}).
+		Where("id = ?", r.Id).
+		Updates(map[string]interface{}{
+			"name":         tenant.Name,
+			"display_name": tenant.DisplayName,
+			"time_zone":    tenant.TimeZone,
+			"locale":       tenant.Locale,
+			"settings":     tenant.Settings,
+			"update_time":  time.Now().Local(),
+		}).Error
+	if err != nil {
+		return err
+	}
+	shared.InvalidateCache(shared.TenantsCacheKey)
+
+	return nil
+}
+
+// 禁用或者启用租户
+func SetTenantDisabled(id int, disabled bool) error {
+	_, err := GetTenant(id)
+	if err != nil {
+		return err
+	}
+
+	err = global.BankDb.
+		Model(&model.Tenant{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"is_disabled": disabled,
+			"update_time": time.Now().Local(),
+		}).Error
+	if err != nil {
+		return err
+	}
+	shared.InvalidateCache(shared.TenantsCacheKey)
+
+	return nil
+}
+
+// 获取租户列表
+func ListTenants(r *request.TenantListRequest) (*response.PagingResponse, error) {
+	var tenants []model.Tenant
+
+	err := shared.ResolveSort(&r.PagingRequest, map[string]string{
+		"":            "id",
+		"id":          "id",
+		"name":        "name",
+		"create_time": "create_time",
+		"update_time": "update_time",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	db := global.BankDb.Model(&model.Tenant{})
+	if r.Keyword != "" {
+		if err := util.ValidateKeyword(r.Keyword); err != nil {
+			return nil, err
+		}
+		db = db.Where("name ~ ? or display_name ~ ?", r.Keyword, r.Keyword)
+	}
+	if r.IsDisabled != nil {
+		db = db.Where("is_disabled = ?", *r.IsDisabled)
+	}
+
+	var count int64
+	db.Count(&count)
+
+	db = shared.ApplyPaging(db, &r.PagingRequest)
+	err = db.Find(&tenants).Error
+
+	return &response.PagingResponse{
+		TotalCount:   count,
+		CurrentCount: int64(len(tenants)),
+		Data:         &tenants,
+	}, err
+}
@@ -0,0 +1,177 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/21 10:40
+ * @Desc: 流程变量
+ */
+package service
+
+import (
+	"sort"
+
+	"github.com/labstack/echo/v4"
+
+	"workflow/src/global"
+	"workflow/src/global/shared"
+	"workflow/src/model"
+	"workflow/src/model/request"
+	"workflow/src/model/response"
+	"workflow/src/util"
+)
+
+// 时间线中的类型
+const (
+	TimelineCirculation = "circulation"
+	TimelineVariable    = "variable"
+)
+
+// 获取流程实例的变量列表, 按照变量名排序
+func ListInstanceVariables(r *request.GetVariableListRequest, c echo.Context) ([]response.InstanceVariableResponse, error) {
+	tenantId, userIdentifier := util.GetWorkContext(c)
+
+	// 必须有权限查看流程实例才能查看变量
+	instance, err := getVisibleInstance(r.InstanceId, userIdentifier, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	variables := util.UnmarshalToInstanceVariables(instance.Variables)
+	sort.Slice(variables, func(i, j int) bool {
+		return variables[i].Name < variables[j].Name
+	})
+
+	result := make([]response.InstanceVariableResponse, 0, len(variables))
+	for _, variable := range variables {
+		result = append(result, toVariableResponse(variable))
+	}
+
+	return result, nil
+}
+
+// 获取流程实例的单个变量
+func GetInstanceVariable(r *request.GetVariableRequest, c echo.Context) (*response.InstanceVariableResponse, error) {
+	tenantId, userIdentifier := util.GetWorkContext(c)
+
+	instance, err := getVisibleInstance(r.InstanceId, userIdentifier, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, variable := range util.UnmarshalToInstanceVariables(instance.Variables) {
+		if variable.Name == r.VariableName {
+			resp := toVariableResponse(variable)
+			return &resp, nil
+		}
+	}
+
+	return nil, util.NotFound.Newf("变量%s不存在", r.VariableName)
+}
+
+// 获取流程实例变量的变更历史
+func ListVariableHistory(r *request.GetVariableListRequest, c echo.Context) (*response.PagingResponse, error) {
+	var (
+		histories                []model.VariableHistory
+		tenantId, userIdentifier = util.GetWorkContext(c)
+	)
+
+	_, err := getVisibleInstance(r.InstanceId, userIdentifier, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = shared.ResolveSort(&r.PagingRequest, map[string]string{
+		"":            "id",
+		"id":          "id",
+		"create_time": "create_time",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	db := global.BankDb.
+		Model(&model.VariableHistory{}).
+		Where("process_instance_id = ?", r.InstanceId).
+		Where("tenant_id = ?", tenantId)
+	if r.Name != "" {
+		db = db.Where("name = ?", r.Name)
+	}
+
+	var count int64
+	db.Count(&count)
+
+	db = shared.ApplyPaging(db, &r.PagingRequest)
+	err = db.Find(&histories).Error
+
+	return &response.PagingResponse{
+		TotalCount:   count,
+		CurrentCount: int64(len(histories)),
+		Data:         &histories,
+	}, err
+}
+
+// 获取流程实例的时间线, 包括流转记录和变量变更, 按照时间先后排列
+func GetInstanceTimeline(instanceId int, c echo.Context) ([]response.TimelineItem, error) {
+	tenantId, userIdentifier := util.GetWorkContext(c)
+
+	_, err := getVisibleInstance(instanceId, userIdentifier, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	var circulations []model.CirculationHistory
+	err = global.BankDb.
+		Where("process_instance_id = ?", instanceId).
+		Order("id").
+		Find(&circulations).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	var variableChanges []model.VariableHistory
+	err = global.BankDb.
+		Where("process_instance_id = ?", instanceId).
+		Where("tenant_id = ?", tenantId).
+		Order("id").
+		Find(&variableChanges).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := make([]response.TimelineItem, 0, len(circulations)+len(variableChanges))
+	for i := range circulations {
+		timeline = append(timeline, response.TimelineItem{
+			Type:        TimelineCirculation,
+			Time:        circulations[i].CreateTime,
+			Circulation: &circulations[i],
+		})
+	}
+	for i := range variableChanges {
+		timeline = append(timeline, response.TimelineItem{
+			Type:           TimelineVariable,
+			Time:           variableChanges[i].CreateTime,
+			VariableChange: &variableChanges[i],
+		})
+	}
+
+	// 同一时间的流转记录排在变量变更前面
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Time.Before(timeline[j].Time)
+	})
+
+	return timeline, nil
+}
+
+// 早期没有类型的变量根据值推断类型
+func toVariableResponse(variable model.InstanceVariable) response.InstanceVariableResponse {
+	variableType := variable.Type
+	if variableType == "" {
+		variableType = util.InferVariableType(variable.Value)
+	}
+
+	return response.InstanceVariableResponse{
+		Name:  variable.Name,
+		Type:  variableType,
+		Value: variable.Value,
+	}
+}
@@ -0,0 +1,167 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/16 9:40
+ * @Desc: 流程实例的可见性
+ */
+package service
+
+import (
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+
+	"workflow/src/global"
+	"workflow/src/model"
+	"workflow/src/util"
+)
+
+// 检查当前用户是否可以查看流程实例, 满足以下任意一条即可:
+// 1. 租户管理员
+// 2. 发起人、当前处理人、相关人、曾经被分配过任务的人
+// 3. 流程定义上配置的观察者用户或者观察者角色
+// 4. 流程定义开启了viewerManagers时, 发起人汇报链上的各级上级
+func CheckInstanceVisible(instance *model.ProcessInstance, userIdentifier string, tenantId int) error {
+	if instance.CreateBy == userIdentifier || util.SliceAnyString(instance.RelatedPerson, userIdentifier) {
+		return nil
+	}
+	for _, state := range instance.State {
+		if util.SliceAnyString(state.Processor, userIdentifier) {
+			return nil
+		}
+	}
+
+	var count int64
+	global.BankDb.Model(&model.Task{}).
+		Where("process_instance_id = ?", instance.Id).
+		Where("tenant_id = ?", tenantId).
+		Where("assignee = ?", userIdentifier).
+		Count(&count)
+	if count > 0 {
+		return nil
+	}
+
+	roles, err := GetUserRoleIdentifiers(userIdentifier, tenantId)
+	if err != nil {
+		return err
+	}
+	if isTenantAdmin(roles) {
+		return nil
+	}
+
+	var definition model.ProcessDefinition
+	err = global.BankDb.
+		Where("id = ?", instance.ProcessDefinitionId).
+		Where("tenant_id = ?", tenantId).
+		Select("id, viewer_users, viewer_roles, viewer_managers").
+		First(&definition).
+		Error
+	if err == nil && isDefinitionViewer(&definition, userIdentifier, roles) {
+		return nil
+	}
+	if err == nil && definition.ViewerManagers {
+		isManager, err := isManagerOf(userIdentifier, instance.CreateBy, tenantId)
+		if err != nil {
+			return err
+		}
+		if isManager {
+			return nil
+		}
+	}
+
+	// 没有权限的时候和不存在一样处理, 避免暴露其他流程实例是否存在
+	return util.NotFound.New("记录不存在")
+}
+
+// 根据id获取当前用户可以查看的流程实例
+func getVisibleInstance(id int, userIdentifier string, tenantId int) (*model.ProcessInstance, error) {
+	var instance model.ProcessInstance
+	err := global.BankDb.
+		Where("id = ?", id).
+		Where("tenant_id = ?", tenantId).
+		First(&instance).
+		Error
+	if err != nil {
+		return nil, util.NotFound.New("记录不存在")
+	}
+
+	err = CheckInstanceVisible(&instance, userIdentifier, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &instance, nil
+}
+
+// 过滤出当前用户可以查看的流程实例, 规则和CheckInstanceVisible保持一致
+func applyInstanceVisibility(db *gorm.DB, userIdentifier string, tenantId int) (*gorm.DB, error) {
+	roles, err := GetUserRoleIdentifiers(userIdentifier, tenantId)
+	if err != nil {
+		return nil, err
+	}
+	if isTenantAdmin(roles) {
+		return db, nil
+	}
+
+	subordinates, err := getSubordinateIdentifiers(userIdentifier, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.Where("process_instance.create_by = ? or ? = any(process_instance.related_person) "+
+		"or exists (select 1 from wf.task where task.process_instance_id = process_instance.id and task.tenant_id = ? and task.assignee = ?) "+
+		"or exists (select 1 from wf.process_definition where process_definition.id = process_instance.process_definition_id and (? = any(process_definition.viewer_users) or process_definition.viewer_roles && ? "+
+		"or (process_definition.viewer_managers and process_instance.create_by = any(?))))",
+		userIdentifier, userIdentifier, tenantId, userIdentifier, userIdentifier, pq.StringArray(roles), pq.StringArray(subordinates)), nil
+}
+
+// 用户是否在发起人的汇报链上(发起人的各级上级), union会去重, 汇报关系存在循环时也能结束
+func isManagerOf(userIdentifier string, initiator string, tenantId int) (bool, error) {
+	if userIdentifier == "" || userIdentifier == initiator {
+		return false, nil
+	}
+
+	var count int64
+	err := global.BankDb.Raw("with recursive managers as ("+
+		"select manager_identifier as identifier from wf.user where tenant_id = ? and identifier = ? "+
+		"union select u.manager_identifier from wf.user u inner join managers m on u.identifier = m.identifier where u.tenant_id = ?"+
+		") select count(1) from managers where identifier = ?",
+		tenantId, initiator, tenantId, userIdentifier).
+		Scan(&count).
+		Error
+
+	return count > 0, err
+}
+
+// 获取用户的各级下属, 用于过滤开启了viewerManagers的流程实例
+func getSubordinateIdentifiers(userIdentifier string, tenantId int) ([]string, error) {
+	subordinates := make([]string, 0)
+	err := global.BankDb.Raw("with recursive subordinates as ("+
+		"select identifier from wf.user where tenant_id = ? and manager_identifier = ? "+
+		"union select u.identifier from wf.user u inner join subordinates s on u.manager_identifier = s.identifier where u.tenant_id = ?"+
+		") select identifier from subordinates where identifier <> ?",
+		tenantId, userIdentifier, tenantId, userIdentifier).
+		Scan(&subordinates).
+		Error
+
+	return subordinates, err
+}
+
+// 是否是租户管理员
+func isTenantAdmin(roles []string) bool {
+	adminRole := global.BankConfig.App.AdminRole
+
+	return adminRole != "" && util.SliceAnyString(roles, adminRole)
+}
+
+// 是否是流程定义上配置的观察者
+func isDefinitionViewer(definition *model.ProcessDefinition, userIdentifier string, roles []string) bool {
+	if util.SliceAnyString(definition.ViewerUsers, userIdentifier) {
+		return true
+	}
+	for _, role := range roles {
+		if util.SliceAnyString(definition.ViewerRoles, role) {
+			return true
+		}
+	}
+
+	return false
+}
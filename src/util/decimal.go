@@ -0,0 +1,130 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/22 10:40
+ * @Desc: decimal类型的变量在表达式中的精确计算
+ */
+package util
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// decimal类型的变量在表达式中的值, 比较和四则运算都按照big.Rat精确计算
+// 可以和数字字面量以及number类型的变量直接比较, 比如 amount == 0.3
+type Decimal struct {
+	*big.Rat
+}
+
+// 解析十进制的小数字符串, 不支持分数的写法
+func ParseDecimal(s string) (Decimal, bool) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		return Decimal{}, false
+	}
+
+	rat, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Decimal{}, false
+	}
+
+	return Decimal{Rat: rat}, true
+}
+
+// 转换成十进制的字符串, 除不尽的时候保留20位小数
+func (d Decimal) String() string {
+	if d.Rat == nil {
+		return "0"
+	}
+	if d.IsInt() {
+		return d.Num().String()
+	}
+
+	s := strings.TrimRight(d.FloatString(20), "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// float64按照最短的十进制表示转换, 比如0.3转换成3/10而不是0.3的二进制近似值
+func floatRat(f float64) *big.Rat {
+	rat, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	if !ok {
+		panic(fmt.Errorf("%v不能转换成精确小数", f))
+	}
+
+	return rat
+}
+
+func intRat(i int) *big.Rat {
+	return new(big.Rat).SetInt64(int64(i))
+}
+
+// decimal的比较运算符, 根据big.Rat.Cmp的结果判断
+var decimalComparisons = []struct {
+	operator string
+	name     string
+	test     func(cmp int) bool
+}{
+	{"<", "Less", func(cmp int) bool { return cmp < 0 }},
+	{"<=", "LessEqual", func(cmp int) bool { return cmp <= 0 }},
+	{">", "Greater", func(cmp int) bool { return cmp > 0 }},
+	{">=", "GreaterEqual", func(cmp int) bool { return cmp >= 0 }},
+	{"==", "Equal", func(cmp int) bool { return cmp == 0 }},
+	{"!=", "NotEqual", func(cmp int) bool { return cmp != 0 }},
+}
+
+// decimal的四则运算, 结果仍然是decimal
+// 注意: 直接作为函数参数的运算(比如toString(amount * 2))会被expr当作普通数值运算, 不会走重载
+var decimalArithmetics = []struct {
+	operator string
+	name     string
+	calc     func(a, b *big.Rat) *big.Rat
+}{
+	{"+", "Add", func(a, b *big.Rat) *big.Rat { return new(big.Rat).Add(a, b) }},
+	{"-", "Sub", func(a, b *big.Rat) *big.Rat { return new(big.Rat).Sub(a, b) }},
+	{"*", "Mul", func(a, b *big.Rat) *big.Rat { return new(big.Rat).Mul(a, b) }},
+	{"/", "Quo", func(a, b *big.Rat) *big.Rat {
+		if b.Sign() == 0 {
+			panic(errors.New("除数不能为0"))
+		}
+		return new(big.Rat).Quo(a, b)
+	}},
+}
+
+// 注册decimal的运算符重载, 运算符重载要求参数的类型完全匹配
+// 所以需要分别注册 decimal和decimal、decimal和数字、数字和decimal 的函数
+func init() {
+	for _, comparison := range decimalComparisons {
+		test := comparison.test
+		cmp := func(a, b *big.Rat) bool { return test(a.Cmp(b)) }
+		registerDecimalOperator(comparison.operator, "decimal"+comparison.name, []interface{}{
+			func(a, b Decimal) bool { return cmp(a.Rat, b.Rat) },
+			func(a Decimal, b float64) bool { return cmp(a.Rat, floatRat(b)) },
+			func(a float64, b Decimal) bool { return cmp(floatRat(a), b.Rat) },
+			func(a Decimal, b int) bool { return cmp(a.Rat, intRat(b)) },
+			func(a int, b Decimal) bool { return cmp(intRat(a), b.Rat) },
+		})
+	}
+
+	for _, arithmetic := range decimalArithmetics {
+		calc := arithmetic.calc
+		registerDecimalOperator(arithmetic.operator, "decimal"+arithmetic.name, []interface{}{
+			func(a, b Decimal) Decimal { return Decimal{calc(a.Rat, b.Rat)} },
+			func(a Decimal, b float64) Decimal { return Decimal{calc(a.Rat, floatRat(b))} },
+			func(a float64, b Decimal) Decimal { return Decimal{calc(floatRat(a), b.Rat)} },
+			func(a Decimal, b int) Decimal { return Decimal{calc(a.Rat, intRat(b))} },
+			func(a int, b Decimal) Decimal { return Decimal{calc(intRat(a), b.Rat)} },
+		})
+	}
+}
+
+// 运算符重载的函数不直接在表达式中使用, 按照 前缀+序号 命名
+func registerDecimalOperator(operator string, prefix string, fns []interface{}) {
+	for i, fn := range fns {
+		name := fmt.Sprintf("%s%d", prefix, i)
+		expressionFunctions[name] = fn
+		expressionOperators[operator] = append(expressionOperators[operator], name)
+	}
+}
@@ -0,0 +1,98 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/22 16:30
+ * @Desc: decimal运算符重载的测试
+ */
+package util
+
+import (
+	"testing"
+
+	"workflow/src/model"
+)
+
+func decimalVariable(t *testing.T, s string) Decimal {
+	t.Helper()
+
+	d, ok := ParseDecimal(s)
+	if !ok {
+		t.Fatalf("%s不是合法的decimal", s)
+	}
+
+	return d
+}
+
+func TestDecimalOperators(t *testing.T) {
+	variables := map[string]interface{}{
+		"a":      decimalVariable(t, "0.1"),
+		"b":      decimalVariable(t, "0.2"),
+		"amount": ExpressionValue(model.InstanceVariable{Name: "amount", Type: VariableTypeDecimal, Value: "0.3"}),
+		"big1":   decimalVariable(t, "12345678901234567.01"),
+		"big2":   decimalVariable(t, "12345678901234567.02"),
+		"n":      0.3,
+		"i":      1,
+	}
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{"a + b == 0.3", true},
+		{"0.3 == a + b", true},
+		{"a + b == amount", true},
+		{"amount == 0.3", true},
+		{"amount == n", true},
+		{"amount != 0.30000000000000004", true},
+		{"amount < 1", true},
+		{"1 > amount", true},
+		{"amount < i", true},
+		{"amount <= 0.3", true},
+		{"amount >= 0.3", true},
+		{"amount > 0.3", false},
+		{"amount - a == 0.2", true},
+		{"amount * 10 == 3", true},
+		{"3 == amount * 10", true},
+		{"amount / 3 == 0.1", true},
+		{"big1 < big2", true},
+		{"big1 == big2", false},
+		{"big2 - big1 == 0.01", true},
+		{"amount > 0 && amount < 1", true},
+	}
+
+	for _, tt := range tests {
+		got, err := CalculateExpression(tt.expression, variables, nil)
+		if err != nil {
+			t.Errorf("%s: 不应该返回错误: %v", tt.expression, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: 期望%v, 实际为%v", tt.expression, tt.want, got)
+		}
+	}
+
+	if _, err := CalculateExpression("amount / 0 > 1", variables, nil); err == nil {
+		t.Errorf("除数为0的时候应该返回错误")
+	}
+}
+
+func TestDecimalString(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"0.30", "0.3"},
+		{"100", "100"},
+		{"-1.50", "-1.5"},
+		{"12345678901234567.89", "12345678901234567.89"},
+	}
+
+	for _, tt := range tests {
+		if got := decimalVariable(t, tt.value).String(); got != tt.want {
+			t.Errorf("%s: 期望%s, 实际为%s", tt.value, tt.want, got)
+		}
+	}
+
+	if _, ok := ParseDecimal("1/3"); ok {
+		t.Errorf("分数不是合法的decimal")
+	}
+}
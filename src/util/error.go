@@ -20,6 +20,7 @@ const (
 	NotFound                      // 404
 	Forbidden                     // 403
 	InternalServerError           // 500
+	Conflict                      // 409
 )
 
 type customError struct {
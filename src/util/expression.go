@@ -7,28 +7,427 @@ package util
 
 import (
 	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/ast"
+	"github.com/antonmedv/expr/parser"
+	"github.com/antonmedv/expr/vm"
+	"github.com/patrickmn/go-cache"
 )
 
-func CalculateExpression(expression string, env map[string]interface{}) (result bool, err error) {
-	program, err := expr.Compile(expression, expr.Env(env))
+// 表达式中可以使用的函数, 可以通过RegisterExpressionFunction注册自定义的函数
+var (
+	expressionFunctions = map[string]interface{}{
+		// 日期
+		"date":        parseDateOrPanic, // date("2021-04-01") 把字符串转换成日期
+		"now":         func() time.Time { return time.Now().Local() },
+		"today":       func() time.Time { return truncateToDay(time.Now().Local()) },
+		"daysBetween": daysBetween, // daysBetween(start, end) 两个日期之间相差的天数, end早于start为负数
+		"addDays":     func(t time.Time, days int) time.Time { return t.AddDate(0, 0, days) },
+		"isWorkday":   isWorkday, // isWorkday(date) 是否是工作日(周一到周五)
+
+		// 字符串
+		"lower":     strings.ToLower,
+		"upper":     strings.ToUpper,
+		"trim":      strings.TrimSpace,
+		"split":     strings.Split,
+		"replace":   func(s, old, new string) string { return strings.ReplaceAll(s, old, new) },
+		"hasPrefix": strings.HasPrefix,
+		"hasSuffix": strings.HasSuffix,
+		"includes":  strings.Contains,
+		"toString":  func(v interface{}) string { return fmt.Sprint(v) },
+
+		// 以下为date类型的比较运算符重载, 不直接在表达式中使用
+		"dateLess":         func(a, b time.Time) bool { return a.Before(b) },
+		"dateLessEqual":    func(a, b time.Time) bool { return !a.After(b) },
+		"dateGreater":      func(a, b time.Time) bool { return a.After(b) },
+		"dateGreaterEqual": func(a, b time.Time) bool { return !a.Before(b) },
+		"dateEqual":        func(a, b time.Time) bool { return a.Equal(b) },
+		"dateNotEqual":     func(a, b time.Time) bool { return !a.Equal(b) },
+	}
+	expressionFunctionsLock sync.RWMutex
+)
+
+// 运算符重载
+var expressionOperators = map[string][]string{
+	"<":  {"dateLess"},
+	"<=": {"dateLessEqual"},
+	">":  {"dateGreater"},
+	">=": {"dateGreaterEqual"},
+	"==": {"dateEqual"},
+	"!=": {"dateNotEqual"},
+}
+
+// 表达式计算时的上下文, 提供和当前流程实例、外部系统用户角色相关的函数
+type ExpressionContext struct {
+	Initiator        string                               // 流程发起人
+	CurrentProcessor string                               // 当前处理人
+	UserRoles        func(userIdentifier string) []string // 获取用户的角色
+	RoleUsers        func(roleIdentifier string) []string // 获取角色下的用户
+	UserName         func(userIdentifier string) string   // 获取用户的名称
+	CacheScope       string                               // 编译结果的缓存范围, 比如 流程定义id:版本, 为空则不缓存
+}
+
+// 注册自定义的表达式函数, 需要在应用启动时调用, 同名的函数会被覆盖
+// 比如: util.RegisterExpressionFunction("isVip", func(user string) bool { ... })
+func RegisterExpressionFunction(name string, fn interface{}) {
+	if reflect.TypeOf(fn) == nil || reflect.TypeOf(fn).Kind() != reflect.Func {
+		panic(fmt.Sprintf("表达式函数%s必须是func", name))
+	}
+
+	expressionFunctionsLock.Lock()
+	defer expressionFunctionsLock.Unlock()
+	expressionFunctions[name] = fn
+}
+
+// 计算条件表达式, 结果必须是bool类型
+func CalculateExpression(expression string, variables map[string]interface{}, ctx *ExpressionContext) (bool, error) {
+	output, err := EvaluateExpression(expression, variables, ctx)
 	if err != nil {
-		err = fmt.Errorf("处理失败, 请检查表达式和变量")
-		return
+		return false, err
+	}
+
+	result, ok := output.(bool)
+	if !ok {
+		return false, newExpressionError(expression, fmt.Sprintf("的结果必须是bool类型, 当前结果为: %v(%T)", output, output))
 	}
 
+	return result, nil
+}
+
+// 计算表达式并返回原始结果, 变量和函数同名的时候变量优先
+// ctx.CacheScope不为空的时候编译结果按照 范围+表达式+变量类型 缓存
+func EvaluateExpression(expression string, variables map[string]interface{}, ctx *ExpressionContext) (interface{}, error) {
+	limits := getExpressionLimits()
+	if limits.MaxLength > 0 && len(expression) > limits.MaxLength {
+		return nil, newExpressionError(expression, fmt.Sprintf("长度超过了限制%d", limits.MaxLength))
+	}
+
+	env := make(map[string]interface{}, len(expressionFunctions)+len(variables)+6)
+	expressionFunctionsLock.RLock()
+	for name, fn := range expressionFunctions {
+		env[name] = fn
+	}
+	expressionFunctionsLock.RUnlock()
+	for name, fn := range ctx.functions() {
+		env[name] = fn
+	}
+	for name, value := range variables {
+		env[name] = value
+	}
+
+	program, err := getProgram(expression, env, ctx.cacheScope(), limits)
+	if err != nil {
+		return nil, err
+	}
+
+	// 执行量在编译前已经通过范围和闭包的限制控制住, 这里同步执行
 	output, err := expr.Run(program, env)
 	if err != nil {
-		err = fmt.Errorf("处理失败, 请检查表达式和变量")
+		return nil, newExpressionError(expression, "执行失败: "+err.Error())
+	}
+
+	return output, nil
+}
+
+// 表达式的错误, 包括表达式本身和缺少的变量
+type ExpressionError struct {
+	Expression       string
+	Reason           string
+	MissingVariables []string
+}
+
+func (e *ExpressionError) Error() string {
+	message := fmt.Sprintf("表达式[%s]%s", e.Expression, e.Reason)
+	if len(e.MissingVariables) > 0 {
+		message += fmt.Sprintf(", 缺少变量: %s", strings.Join(e.MissingVariables, ", "))
+	}
+
+	return message
+}
+
+func newExpressionError(expression string, reason string) *ExpressionError {
+	return &ExpressionError{Expression: expression, Reason: reason}
+}
+
+// 表达式的执行限制
+type ExpressionLimits struct {
+	MaxLength       int           // 表达式的最大长度, 0为不限制
+	MaxNodes        int           // 语法树的最大节点数, 0为不限制
+	MaxRange        int           // 范围(a..b)的最大元素个数, 0为不限制
+	MaxClosureDepth int           // all/any/none/one/filter/map/count的最大嵌套层数, 0为不限制
+	CacheTtl        time.Duration // 编译结果的缓存时间
+}
+
+var (
+	expressionLimits = ExpressionLimits{
+		MaxLength:       4096,
+		MaxNodes:        1000,
+		MaxRange:        10000,
+		MaxClosureDepth: 2,
+		CacheTtl:        time.Hour,
+	}
+	expressionLimitsLock sync.RWMutex
+	programCache         = cache.New(time.Hour, 10*time.Minute)
+)
+
+// 设置表达式的执行限制, 应用启动时根据配置调用
+func SetExpressionLimits(limits ExpressionLimits) {
+	expressionLimitsLock.Lock()
+	defer expressionLimitsLock.Unlock()
+
+	expressionLimits = limits
+}
+
+func getExpressionLimits() ExpressionLimits {
+	expressionLimitsLock.RLock()
+	defer expressionLimitsLock.RUnlock()
+
+	return expressionLimits
+}
+
+// 获取编译之后的表达式, 优先从缓存中获取
+func getProgram(expression string, env map[string]interface{}, scope string, limits ExpressionLimits) (*vm.Program, error) {
+	var cacheKey string
+	if scope != "" {
+		cacheKey = scope + "\x00" + expression + "\x00" + envSignature(env)
+		if cached, ok := programCache.Get(cacheKey); ok {
+			return cached.(*vm.Program), nil
+		}
+	}
+
+	tree, err := parser.Parse(expression)
+	if err != nil {
+		return nil, newExpressionError(expression, "语法错误: "+err.Error())
+	}
+
+	collector := &variableCollector{exist: make(map[string]bool), limits: limits}
+	ast.Walk(&tree.Node, collector)
+	if limits.MaxNodes > 0 && collector.nodeCount > limits.MaxNodes {
+		return nil, newExpressionError(expression, fmt.Sprintf("过于复杂, 节点数超过了限制%d", limits.MaxNodes))
+	}
+	if collector.limitErr != "" {
+		return nil, newExpressionError(expression, collector.limitErr)
+	}
+
+	options := []expr.Option{expr.Env(env)}
+	for operator, fns := range expressionOperators {
+		options = append(options, expr.Operator(operator, fns...))
+	}
+
+	program, err := expr.Compile(expression, options...)
+	if err != nil {
+		exprErr := newExpressionError(expression, "编译失败: "+err.Error())
+		for _, name := range collector.variables {
+			if _, ok := env[name]; !ok {
+				exprErr.MissingVariables = append(exprErr.MissingVariables, name)
+			}
+		}
+		return nil, exprErr
+	}
+
+	if cacheKey != "" {
+		programCache.Set(cacheKey, program, limits.CacheTtl)
+	}
+
+	return program, nil
+}
+
+// 变量名和类型的签名, 编译结果和变量的类型相关
+func envSignature(env map[string]interface{}) string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var builder strings.Builder
+	for _, name := range names {
+		builder.WriteString(name)
+		builder.WriteString(":")
+		builder.WriteString(fmt.Sprintf("%T", env[name]))
+		builder.WriteString(";")
+	}
+
+	return builder.String()
+}
+
+func (ctx *ExpressionContext) cacheScope() string {
+	if ctx == nil {
+		return ""
+	}
+
+	return ctx.CacheScope
+}
+
+// 上下文相关的函数, 没有上下文的时候返回空值
+func (ctx *ExpressionContext) functions() map[string]interface{} {
+	if ctx == nil {
+		ctx = &ExpressionContext{}
+	}
+
+	return map[string]interface{}{
+		"initiator":        func() string { return ctx.Initiator },
+		"currentProcessor": func() string { return ctx.CurrentProcessor },
+		"rolesOf": func(user string) []string {
+			if ctx.UserRoles == nil {
+				return []string{}
+			}
+			return ctx.UserRoles(user)
+		},
+		"userInRole": func(user string, role string) bool {
+			if ctx.UserRoles == nil {
+				return false
+			}
+			return SliceAnyString(ctx.UserRoles(user), role)
+		},
+		"usersInRole": func(role string) []string {
+			if ctx.RoleUsers == nil {
+				return []string{}
+			}
+			return ctx.RoleUsers(role)
+		},
+		"userName": func(user string) string {
+			if ctx.UserName == nil {
+				return ""
+			}
+			return ctx.UserName(user)
+		},
+	}
+}
+
+func parseDateOrPanic(s string) time.Time {
+	t, err := ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// 按照自然日计算, 不考虑时分秒
+func daysBetween(start time.Time, end time.Time) int {
+	start = truncateToDay(start)
+	end = truncateToDay(end.In(start.Location()))
+
+	return int(math.Round(end.Sub(start).Hours() / 24))
+}
+
+func isWorkday(t time.Time) bool {
+	return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+}
+
+// 获取表达式中引用的变量名(按出现顺序去重), 函数名、属性名不算变量
+func ExpressionVariables(expression string) ([]string, error) {
+	tree, err := parser.Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	collector := &variableCollector{exist: make(map[string]bool)}
+	ast.Walk(&tree.Node, collector)
+
+	return collector.variables, nil
+}
+
+// 遍历语法树, 收集变量名并检查执行量相关的限制
+// 范围的上下界必须是整数常量, 并且不能在闭包中创建; 闭包的嵌套层数有限制
+// 这样执行量只和范围的大小以及变量中列表的长度相关, 不会出现无限制的循环
+type variableCollector struct {
+	variables    []string
+	exist        map[string]bool
+	nodeCount    int
+	limits       ExpressionLimits
+	closureDepth int
+	limitErr     string
+}
+
+func (v *variableCollector) Enter(node *ast.Node) {
+	v.nodeCount++
+
+	switch n := (*node).(type) {
+	case *ast.ClosureNode:
+		v.closureDepth++
+		if v.limits.MaxClosureDepth > 0 && v.closureDepth > v.limits.MaxClosureDepth {
+			v.setLimitErr(fmt.Sprintf("过于复杂, 闭包的嵌套层数超过了限制%d", v.limits.MaxClosureDepth))
+		}
+	case *ast.BinaryNode:
+		if n.Operator == ".." {
+			v.checkRange(n)
+		}
+	}
+}
+
+func (v *variableCollector) Exit(node *ast.Node) {
+	if _, ok := (*node).(*ast.ClosureNode); ok {
+		v.closureDepth--
 		return
 	}
 
-	if v, succeed := output.(bool); succeed {
-		result = v
+	identifier, ok := (*node).(*ast.IdentifierNode)
+	if !ok || v.exist[identifier.Value] {
 		return
 	}
-	
-	err = fmt.Errorf("处理失败, 请检查表达式和变量")
-	return
+
+	v.exist[identifier.Value] = true
+	v.variables = append(v.variables, identifier.Value)
+}
+
+func (v *variableCollector) checkRange(node *ast.BinaryNode) {
+	if v.limits.MaxRange <= 0 {
+		return
+	}
+
+	if v.closureDepth > 0 {
+		v.setLimitErr("不能在闭包中使用范围")
+		return
+	}
+
+	min, minOk := constantInt(node.Left)
+	max, maxOk := constantInt(node.Right)
+	if !minOk || !maxOk {
+		v.setLimitErr("范围的上下界必须是整数常量")
+		return
+	}
+	if max-min+1 > v.limits.MaxRange {
+		v.setLimitErr(fmt.Sprintf("范围的元素个数超过了限制%d", v.limits.MaxRange))
+	}
+}
+
+// 只记录第一个超过限制的原因
+func (v *variableCollector) setLimitErr(reason string) {
+	if v.limitErr == "" {
+		v.limitErr = reason
+	}
+}
+
+// 整数常量, 包括负数
+func constantInt(node ast.Node) (int, bool) {
+	switch n := node.(type) {
+	case *ast.IntegerNode:
+		return n.Value, true
+	case *ast.UnaryNode:
+		value, ok := constantInt(n.Node)
+		if !ok {
+			return 0, false
+		}
+		switch n.Operator {
+		case "-":
+			return -value, true
+		case "+":
+			return value, true
+		}
+	}
+
+	return 0, false
 }
@@ -0,0 +1,144 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/22 17:00
+ * @Desc: 条件表达式的测试
+ */
+package util
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"workflow/src/model"
+)
+
+func TestDateComparisons(t *testing.T) {
+	variables := map[string]interface{}{
+		"d":     ExpressionValue(model.InstanceVariable{Name: "d", Type: VariableTypeDate, Value: "2021-04-01"}),
+		"later": ExpressionValue(model.InstanceVariable{Name: "later", Type: VariableTypeDate, Value: "2021-04-11 09:00"}),
+	}
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{`d < date("2021-05-01")`, true},
+		{`d <= date("2021-04-01")`, true},
+		{`d > date("2021-05-01")`, false},
+		{`d >= later`, false},
+		{`later > d`, true},
+		{`d == date("2021-04-01 00:00:00")`, true},
+		{`d != later`, true},
+		{`daysBetween(d, later) == 10`, true},
+		{`addDays(d, 10) < later`, true},
+		{`isWorkday(d)`, true},
+	}
+
+	for _, tt := range tests {
+		got, err := CalculateExpression(tt.expression, variables, nil)
+		if err != nil {
+			t.Errorf("%s: 不应该返回错误: %v", tt.expression, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: 期望%v, 实际为%v", tt.expression, tt.want, got)
+		}
+	}
+}
+
+func TestMissingVariables(t *testing.T) {
+	variables := map[string]interface{}{"amount": 100.0}
+
+	_, err := CalculateExpression(`amount > 10 && dept == "hr" && level > 3`, variables, nil)
+	var exprErr *ExpressionError
+	if !errors.As(err, &exprErr) {
+		t.Fatalf("期望返回ExpressionError, 实际为%v", err)
+	}
+	if !reflect.DeepEqual(exprErr.MissingVariables, []string{"dept", "level"}) {
+		t.Fatalf("缺少的变量应该为[dept level], 实际为%v", exprErr.MissingVariables)
+	}
+	if !strings.Contains(exprErr.Error(), "缺少变量: dept, level") {
+		t.Fatalf("错误信息中应该包含缺少的变量, 实际为%s", exprErr.Error())
+	}
+
+	// 函数名和属性名不算变量
+	names, err := ExpressionVariables(`lower(user.name) == "a" && amount > 1`)
+	if err != nil {
+		t.Fatalf("解析表达式失败: %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"user", "amount"}) {
+		t.Fatalf("表达式中的变量应该为[user amount], 实际为%v", names)
+	}
+}
+
+func TestExpressionLimits(t *testing.T) {
+	origin := getExpressionLimits()
+	defer SetExpressionLimits(origin)
+	SetExpressionLimits(ExpressionLimits{
+		MaxLength:       100,
+		MaxNodes:        1000,
+		MaxRange:        100,
+		MaxClosureDepth: 2,
+		CacheTtl:        time.Minute,
+	})
+
+	variables := map[string]interface{}{
+		"n":     10,
+		"items": []interface{}{1, 2, 3},
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		reason     string // 为空表示不应该被拒绝
+	}{
+		{"常量范围", `all(1..100, {# > 0})`, ""},
+		{"负数范围", `all(-3..-1, {# < 0})`, ""},
+		{"范围超过限制", `all(1..2000000000, {# >= 0})`, "范围的元素个数超过了限制"},
+		{"非常量的上界", `all(1..n, {# > 0})`, "上下界必须是整数常量"},
+		{"非常量的下界", `all(n..20, {# > 0})`, "上下界必须是整数常量"},
+		{"闭包中的范围", `map(1..3, {1..3})`, "不能在闭包中使用范围"},
+		{"两层闭包", `any(items, {any(items, {# > 2})})`, ""},
+		{"三层闭包", `any(items, {any(items, {any(items, {true})})})`, "闭包的嵌套层数超过了限制"},
+		{"长度超过限制", strings.Repeat("n + ", 30) + "n > 0", "长度超过了限制"},
+	}
+
+	for _, tt := range tests {
+		_, err := CalculateExpression(tt.expression, variables, nil)
+		if tt.reason == "" {
+			if err != nil {
+				t.Errorf("%s: 不应该返回错误: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.reason) {
+			t.Errorf("%s: 期望错误包含[%s], 实际为%v", tt.name, tt.reason, err)
+		}
+	}
+}
+
+func TestProgramCacheByVariableTypes(t *testing.T) {
+	ctx := &ExpressionContext{CacheScope: "test:1"}
+
+	// 相同的表达式和范围, 变量的类型不同的时候不能使用同一个编译结果
+	output, err := EvaluateExpression("x + x", map[string]interface{}{"x": 1}, ctx)
+	if err != nil || output != 2 {
+		t.Fatalf("期望结果为2, 实际为%v, %v", output, err)
+	}
+	output, err = EvaluateExpression("x + x", map[string]interface{}{"x": "a"}, ctx)
+	if err != nil || output != "aa" {
+		t.Fatalf("期望结果为aa, 实际为%v, %v", output, err)
+	}
+
+	// 变量的类型相同的时候使用缓存的编译结果
+	output, err = EvaluateExpression("x + x", map[string]interface{}{"x": 2}, ctx)
+	if err != nil || output != 4 {
+		t.Fatalf("期望结果为4, 实际为%v, %v", output, err)
+	}
+	if envSignature(map[string]interface{}{"x": 1}) == envSignature(map[string]interface{}{"x": "a"}) {
+		t.Fatalf("变量类型不同的时候签名应该不同")
+	}
+}
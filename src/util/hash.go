@@ -0,0 +1,18 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/18 14:05
+ * @Desc:
+ */
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// 计算sha256, 返回16进制字符串
+func Sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,17 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/19 16:20
+ * @Desc: 列表查询的关键词
+ */
+package util
+
+import "regexp"
+
+// 关键词按照postgres的正则(~)匹配, 不合法的正则直接返回400, 避免数据库报错变成500
+func ValidateKeyword(keyword string) error {
+	if _, err := regexp.Compile(keyword); err != nil {
+		return BadRequest.Newf("关键词不是合法的正则表达式: %s", keyword)
+	}
+
+	return nil
+}
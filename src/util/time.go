@@ -19,3 +19,15 @@ func FmtDuration(d time.Duration) string {
 
 	return fmt.Sprintf("%02d小时 %02d分钟", h, m)
 }
+
+// 解析请求中的时间, 支持 yyyy-MM-dd HH:mm:ss, yyyy-MM-dd 和 RFC3339
+func ParseTime(str string) (time.Time, error) {
+	layouts := []string{"2006-01-02 15:04:05", "2006-01-02", time.RFC3339}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, str, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("时间格式不正确: %s", str)
+}
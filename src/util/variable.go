@@ -0,0 +1,189 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/21 15:20
+ * @Desc: 流程变量的类型转换
+ */
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"workflow/src/model"
+)
+
+// 流程变量的类型
+const (
+	VariableTypeNumber  = "number"  // 数字, 保存为float64
+	VariableTypeString  = "string"  // 字符串
+	VariableTypeBool    = "bool"    // 布尔
+	VariableTypeDate    = "date"    // 日期时间, 保存为RFC3339格式的字符串, 表达式中为time.Time
+	VariableTypeDecimal = "decimal" // 精确小数, 保存为字符串避免精度丢失, 表达式中按照big.Rat精确比较和计算
+	VariableTypeList    = "list"    // 数组
+	VariableTypeObject  = "object"  // 对象, 表达式中可以通过a.b[0].c访问
+)
+
+// 输入的日期支持的格式
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// 按照变量的类型转换变量的值, 没有指定类型的不做转换
+func CoerceVariable(variable *model.InstanceVariable) error {
+	if variable.Type == "" || variable.Value == nil {
+		return nil
+	}
+
+	value, err := coerceValue(variable.Type, variable.Value)
+	if err != nil {
+		return fmt.Errorf("变量%s的值不是合法的%s类型: %s", variable.Name, variable.Type, err.Error())
+	}
+	variable.Value = value
+
+	return nil
+}
+
+// 没有指定类型的变量根据值推断类型
+func InferVariableTypes(variables []model.InstanceVariable) {
+	for i := range variables {
+		if variables[i].Type == "" {
+			variables[i].Type = InferVariableType(variables[i].Value)
+		}
+	}
+}
+
+// 根据json反序列化之后的值推断变量的类型, nil返回空字符串
+func InferVariableType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return ""
+	case bool:
+		return VariableTypeBool
+	case float64, float32, int, int64:
+		return VariableTypeNumber
+	case string:
+		return VariableTypeString
+	case []interface{}:
+		return VariableTypeList
+	default:
+		return VariableTypeObject
+	}
+}
+
+// 变量在表达式中的值: date转换成time.Time, decimal转换成Decimal, 其他保持不变
+func ExpressionValue(variable model.InstanceVariable) interface{} {
+	switch variable.Type {
+	case VariableTypeDate:
+		if s, ok := variable.Value.(string); ok {
+			if t, err := ParseDate(s); err == nil {
+				return t
+			}
+		}
+	case VariableTypeDecimal:
+		if s, ok := variable.Value.(string); ok {
+			if d, ok := ParseDecimal(s); ok {
+				return d
+			}
+		}
+	}
+
+	return variable.Value
+}
+
+// 按照支持的格式解析日期, 没有时区的当作本地时间
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("不支持的日期格式: %s", s)
+}
+
+func coerceValue(variableType string, value interface{}) (interface{}, error) {
+	switch variableType {
+	case VariableTypeNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case string:
+			return strconv.ParseFloat(strings.TrimSpace(v), 64)
+		}
+
+	case VariableTypeString:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+
+	case VariableTypeBool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			return strconv.ParseBool(strings.TrimSpace(v))
+		}
+
+	case VariableTypeDate:
+		switch v := value.(type) {
+		case string:
+			t, err := ParseDate(v)
+			if err != nil {
+				return nil, err
+			}
+			return t.Format(time.RFC3339), nil
+		case float64:
+			// 数字当作毫秒时间戳
+			return time.Unix(0, int64(v)*int64(time.Millisecond)).Local().Format(time.RFC3339), nil
+		}
+
+	case VariableTypeDecimal:
+		var s string
+		switch v := value.(type) {
+		case string:
+			s = strings.TrimSpace(v)
+		case float64:
+			s = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		if _, ok := ParseDecimal(s); ok {
+			return s, nil
+		}
+
+	case VariableTypeList:
+		switch v := value.(type) {
+		case []interface{}:
+			return v, nil
+		case string:
+			var list []interface{}
+			err := json.Unmarshal([]byte(v), &list)
+			return list, err
+		}
+
+	case VariableTypeObject:
+		switch v := value.(type) {
+		case map[string]interface{}:
+			return v, nil
+		case string:
+			var object map[string]interface{}
+			err := json.Unmarshal([]byte(v), &object)
+			return object, err
+		}
+
+	default:
+		return nil, fmt.Errorf("不支持的变量类型")
+	}
+
+	return nil, fmt.Errorf("当前值的类型为%T", value)
+}
@@ -0,0 +1,105 @@
+/**
+ * @Author: lzw5399
+ * @Date: 2021/4/22 16:10
+ * @Desc: 流程变量类型转换的测试
+ */
+package util
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"workflow/src/model"
+)
+
+func TestCoerceValue(t *testing.T) {
+	date := time.Date(2021, 4, 1, 0, 0, 0, 0, time.Local).Format(time.RFC3339)
+	dateTime := time.Date(2021, 4, 1, 8, 30, 0, 0, time.Local).Format(time.RFC3339)
+
+	tests := []struct {
+		name         string
+		variableType string
+		value        interface{}
+		want         interface{}
+		wantErr      bool
+	}{
+		{"number数字", VariableTypeNumber, 1.5, 1.5, false},
+		{"number字符串", VariableTypeNumber, " 2.5 ", 2.5, false},
+		{"number不合法", VariableTypeNumber, "abc", nil, true},
+		{"number布尔", VariableTypeNumber, true, nil, true},
+		{"string字符串", VariableTypeString, "abc", "abc", false},
+		{"string数字", VariableTypeString, 1.5, "1.5", false},
+		{"string布尔", VariableTypeString, true, "true", false},
+		{"bool布尔", VariableTypeBool, false, false, false},
+		{"bool字符串", VariableTypeBool, "true", true, false},
+		{"bool不合法", VariableTypeBool, "yes", nil, true},
+		{"date日期", VariableTypeDate, "2021-04-01", date, false},
+		{"date日期时间", VariableTypeDate, "2021-04-01 08:30", dateTime, false},
+		{"date毫秒时间戳", VariableTypeDate, float64(0), time.Unix(0, 0).Local().Format(time.RFC3339), false},
+		{"date不合法", VariableTypeDate, "2021/04/01", nil, true},
+		{"decimal字符串", VariableTypeDecimal, " 12345678901234567.89 ", "12345678901234567.89", false},
+		{"decimal数字", VariableTypeDecimal, 0.3, "0.3", false},
+		{"decimal分数", VariableTypeDecimal, "1/3", nil, true},
+		{"decimal不合法", VariableTypeDecimal, "abc", nil, true},
+		{"list数组", VariableTypeList, []interface{}{"a", 1.0}, []interface{}{"a", 1.0}, false},
+		{"list字符串", VariableTypeList, `["a", 1]`, []interface{}{"a", 1.0}, false},
+		{"list不合法", VariableTypeList, `{"a": 1}`, nil, true},
+		{"object对象", VariableTypeObject, map[string]interface{}{"a": 1.0}, map[string]interface{}{"a": 1.0}, false},
+		{"object字符串", VariableTypeObject, `{"a": {"b": [1]}}`, map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{1.0}}}, false},
+		{"object不合法", VariableTypeObject, 1.0, nil, true},
+		{"不支持的类型", "money", 1.0, nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := coerceValue(tt.variableType, tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: 期望返回错误, 实际结果为%v", tt.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: 不应该返回错误: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: 期望%#v, 实际为%#v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestCoerceVariable(t *testing.T) {
+	variable := model.InstanceVariable{Name: "amount", Type: VariableTypeNumber, Value: "abc"}
+	if err := CoerceVariable(&variable); err == nil {
+		t.Fatalf("不合法的值应该返回错误")
+	}
+
+	// 没有指定类型的不做转换
+	variable = model.InstanceVariable{Name: "amount", Value: "1.5"}
+	if err := CoerceVariable(&variable); err != nil || variable.Value != "1.5" {
+		t.Fatalf("没有指定类型的变量不应该转换, 实际为%v, %v", variable.Value, err)
+	}
+}
+
+func TestExpressionValue(t *testing.T) {
+	date := ExpressionValue(model.InstanceVariable{Name: "d", Type: VariableTypeDate, Value: "2021-04-01T00:00:00+08:00"})
+	if _, ok := date.(time.Time); !ok {
+		t.Fatalf("date类型的变量应该转换成time.Time, 实际为%T", date)
+	}
+
+	decimal := ExpressionValue(model.InstanceVariable{Name: "amount", Type: VariableTypeDecimal, Value: "0.30"})
+	d, ok := decimal.(Decimal)
+	if !ok {
+		t.Fatalf("decimal类型的变量应该转换成Decimal, 实际为%T", decimal)
+	}
+	if d.String() != "0.3" {
+		t.Fatalf("Decimal的字符串应该为0.3, 实际为%s", d.String())
+	}
+
+	// 不合法的值保持不变
+	invalid := ExpressionValue(model.InstanceVariable{Name: "amount", Type: VariableTypeDecimal, Value: "abc"})
+	if invalid != "abc" {
+		t.Fatalf("不合法的decimal应该保持原值, 实际为%v", invalid)
+	}
+}